@@ -0,0 +1,336 @@
+package main
+
+// Anomaly detection — rules that flag agents which look stuck or unhealthy.
+//
+// The engine watches two things: transitions, seen as each update replaces
+// an agent (entering Error, an ISC criterion going from passed to failed, a
+// phase change), and conditions, re-checked on every update and tick (no
+// activity, too long in one phase, throughput below the model's floor).
+// Each rule's threshold comes from AlertRules; the config file's [alerts]
+// table overrides the defaults, and a zero value turns a rule off:
+//
+//	[alerts]
+//	stall_after       = "90s"
+//	phase_stuck_after = "10m"
+//	min_tok_ratio     = 0.5
+//	slow_samples      = 3
+//	error_loop_count  = 3
+//	error_loop_window = "5m"
+//	isc_regression    = true
+//
+// --alert-rules FILE reads the same keys from a JSON object instead and wins
+// over the table:
+//
+//	{"stall_after": "90s", "phase_stuck_after": "10m", "min_tok_ratio": 0.5}
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const alertLogCap = 50
+
+// AlertRules holds the thresholds for every rule.
+type AlertRules struct {
+	StallAfter      duration `toml:"stall_after" json:"stall_after"`             // running with no activity for this long
+	PhaseStuckAfter duration `toml:"phase_stuck_after" json:"phase_stuck_after"` // running in one phase for this long
+	MinTokRatio     float64  `toml:"min_tok_ratio" json:"min_tok_ratio"`         // fraction of the model's low-end tok/s
+	SlowSamples     int      `toml:"slow_samples" json:"slow_samples"`           // consecutive history samples below it
+	ErrorLoopCount  int      `toml:"error_loop_count" json:"error_loop_count"`   // errors within ErrorLoopWindow
+	ErrorLoopWindow duration `toml:"error_loop_window" json:"error_loop_window"`
+	ISCRegression   bool     `toml:"isc_regression" json:"isc_regression"` // a passed criterion fails again
+}
+
+func defaultAlertRules() AlertRules {
+	return AlertRules{
+		StallAfter:      duration(60 * time.Second),
+		PhaseStuckAfter: duration(5 * time.Minute),
+		MinTokRatio:     0.5,
+		SlowSamples:     3,
+		ErrorLoopCount:  3,
+		ErrorLoopWindow: duration(5 * time.Minute),
+		ISCRegression:   true,
+	}
+}
+
+// loadAlertRules reads an --alert-rules file on top of the defaults.
+func loadAlertRules(path string) (AlertRules, error) {
+	r := defaultAlertRules()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return r, fmt.Errorf("alert rules: %w", err)
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("alert rules: parse %s: %w", path, err)
+	}
+	if err := r.check(); err != nil {
+		return r, fmt.Errorf("alert rules: %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// check rejects negative thresholds.
+func (r AlertRules) check() error {
+	for _, t := range []struct {
+		name string
+		v    float64
+	}{
+		{"stall_after", float64(r.StallAfter)}, {"phase_stuck_after", float64(r.PhaseStuckAfter)},
+		{"min_tok_ratio", r.MinTokRatio}, {"slow_samples", float64(r.SlowSamples)},
+		{"error_loop_count", float64(r.ErrorLoopCount)}, {"error_loop_window", float64(r.ErrorLoopWindow)},
+	} {
+		if t.v < 0 {
+			return fmt.Errorf("alerts.%s must not be negative", t.name)
+		}
+	}
+	return nil
+}
+
+type alertKind int
+
+const (
+	alertStall alertKind = iota
+	alertPhaseStuck
+	alertSlow
+	alertErrorLoop
+	alertISCRegression
+)
+
+var alertKindNames = [...]string{"stalled", "phase stuck", "slow", "error loop", "ISC regression"}
+
+func (k alertKind) String() string { return alertKindNames[k] }
+
+// alert is one rule currently firing for one agent.
+type alert struct {
+	Kind    alertKind
+	Message string
+	Since   time.Time
+}
+
+// alertEvent is a line in the alert log.
+type alertEvent struct {
+	Time    time.Time
+	AgentID string
+	Name    string
+	Kind    alertKind
+	Message string
+	Cleared bool
+}
+
+type phaseMark struct {
+	phase Phase
+	since time.Time
+}
+
+// alertEngine tracks what each rule needs between updates. It is owned by
+// the model and only touched from Update.
+type alertEngine struct {
+	rules      AlertRules
+	active     map[string]map[alertKind]alert // agent ID → firing rules
+	phaseSince map[string]phaseMark
+	errors     map[string][]time.Time     // times the agent entered StatusError
+	regressed  map[string]map[string]bool // agent ID → criteria that went passed → failed
+	log        []alertEvent               // newest last
+}
+
+func newAlertEngine(rules AlertRules) *alertEngine {
+	return &alertEngine{
+		rules:      rules,
+		active:     map[string]map[alertKind]alert{},
+		phaseSince: map[string]phaseMark{},
+		errors:     map[string][]time.Time{},
+		regressed:  map[string]map[string]bool{},
+	}
+}
+
+// observe records the transitions between two versions of an agent.
+func (e *alertEngine) observe(prev, next Agent, now time.Time) {
+	if next.Status == StatusError && prev.Status != StatusError {
+		e.errors[next.ID] = append(e.errors[next.ID], now)
+	}
+	if next.Phase != prev.Phase {
+		e.phaseSince[next.ID] = phaseMark{next.Phase, now}
+	}
+
+	was := make(map[string]bool, len(prev.ISCItems))
+	for _, c := range prev.ISCItems {
+		was[c.Text] = c.Passed
+	}
+	reg := e.regressed[next.ID]
+	for _, c := range next.ISCItems {
+		switch {
+		case c.Passed:
+			delete(reg, c.Text)
+		case was[c.Text]:
+			if reg == nil {
+				reg = map[string]bool{}
+				e.regressed[next.ID] = reg
+			}
+			reg[c.Text] = true
+		}
+	}
+	// A fresh start (back to OBSERVE) clears the slate.
+	if next.Phase == PhaseObserve && prev.Phase != PhaseObserve {
+		delete(e.regressed, next.ID)
+	}
+}
+
+// evaluate re-checks every rule, logs rules that start or stop firing, and
+// forgets agents that are gone.
+func (e *alertEngine) evaluate(agents []Agent, history map[string]*agentHistory, now time.Time) {
+	present := make(map[string]bool, len(agents))
+	for _, a := range agents {
+		present[a.ID] = true
+		if _, ok := e.phaseSince[a.ID]; !ok {
+			e.phaseSince[a.ID] = phaseMark{a.Phase, now}
+		}
+		firing := e.check(a, history[a.ID], now)
+		cur := e.active[a.ID]
+		for k, al := range firing {
+			if old, ok := cur[k]; ok {
+				al.Since = old.Since
+				firing[k] = al
+				continue
+			}
+			e.record(alertEvent{Time: now, AgentID: a.ID, Name: a.Name, Kind: k, Message: al.Message})
+		}
+		for k, al := range cur {
+			if _, ok := firing[k]; !ok {
+				e.record(alertEvent{Time: now, AgentID: a.ID, Name: a.Name, Kind: k, Message: al.Message, Cleared: true})
+			}
+		}
+		if len(firing) == 0 {
+			delete(e.active, a.ID)
+		} else {
+			e.active[a.ID] = firing
+		}
+	}
+	for id := range e.active {
+		if !present[id] {
+			delete(e.active, id)
+		}
+	}
+	for id := range e.phaseSince {
+		if !present[id] {
+			delete(e.phaseSince, id)
+			delete(e.errors, id)
+			delete(e.regressed, id)
+		}
+	}
+}
+
+// check returns the rules firing for one agent.
+func (e *alertEngine) check(a Agent, h *agentHistory, now time.Time) map[alertKind]alert {
+	r := e.rules
+	out := map[alertKind]alert{}
+	fire := func(k alertKind, format string, args ...any) {
+		out[k] = alert{Kind: k, Message: fmt.Sprintf(format, args...), Since: now}
+	}
+	running := a.Status == StatusRunning
+
+	if d := time.Duration(r.StallAfter); running && d > 0 && !a.LastActTime.IsZero() {
+		if idle := now.Sub(a.LastActTime); idle > d {
+			fire(alertStall, "no activity for %s", fmtDuration(idle))
+		}
+	}
+	if d := time.Duration(r.PhaseStuckAfter); running && d > 0 && a.Phase < PhaseDone {
+		if pm := e.phaseSince[a.ID]; pm.phase == a.Phase && now.Sub(pm.since) > d {
+			fire(alertPhaseStuck, "in %s for %s", a.Phase, fmtDuration(now.Sub(pm.since)))
+		}
+	}
+	if rng, ok := modelTokRanges[a.Model]; ok && running && r.MinTokRatio > 0 && r.SlowSamples > 0 && h != nil {
+		floor := rng[0] * r.MinTokRatio
+		vals := h.tokRate.values()
+		if len(vals) >= r.SlowSamples {
+			slow := true
+			for _, v := range vals[len(vals)-r.SlowSamples:] {
+				slow = slow && v < floor
+			}
+			if slow {
+				fire(alertSlow, "%.0f tok/s, under %.0f%% of %s's %.0f tok/s floor",
+					vals[len(vals)-1], r.MinTokRatio*100, a.Model, rng[0])
+			}
+		}
+	}
+	if n, w := r.ErrorLoopCount, time.Duration(r.ErrorLoopWindow); n > 0 && w > 0 {
+		times := e.errors[a.ID]
+		for len(times) > 0 && now.Sub(times[0]) > w {
+			times = times[1:]
+		}
+		e.errors[a.ID] = times
+		if len(times) >= n {
+			fire(alertErrorLoop, "%d errors in %s", len(times), fmtDuration(w))
+		}
+	}
+	if reg := e.regressed[a.ID]; r.ISCRegression && len(reg) > 0 {
+		texts := make([]string, 0, len(reg))
+		for t := range reg {
+			texts = append(texts, t)
+		}
+		sort.Strings(texts)
+		fire(alertISCRegression, "failing again: %s", strings.Join(texts, "; "))
+	}
+	return out
+}
+
+func (e *alertEngine) record(ev alertEvent) {
+	e.log = append(e.log, ev)
+	if len(e.log) > alertLogCap {
+		e.log = e.log[len(e.log)-alertLogCap:]
+	}
+}
+
+// alertsFor returns the agent's firing rules in rule order.
+func (e *alertEngine) alertsFor(id string) []alert {
+	var out []alert
+	for _, al := range e.active[id] {
+		out = append(out, al)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Kind < out[j].Kind })
+	return out
+}
+
+// count is the number of rules firing across the fleet.
+func (e *alertEngine) count() int {
+	n := 0
+	for _, a := range e.active {
+		n += len(a)
+	}
+	return n
+}
+
+// renderAlertPane lists the most recent alert log entries, newest first.
+func (e *alertEngine) renderAlertPane(w, rows int, th Theme) string {
+	title := lipgloss.NewStyle().Bold(true).Foreground(th.Title)
+	dim := lipgloss.NewStyle().Foreground(th.Dim)
+	warn := lipgloss.NewStyle().Foreground(th.Running)
+	ok := lipgloss.NewStyle().Foreground(th.Idle)
+
+	var b strings.Builder
+	b.WriteString(title.Render("Alerts") + dim.Render(fmt.Sprintf("  %d active", e.count())))
+	if len(e.log) == 0 {
+		b.WriteString("\n" + dim.Render("  No alerts yet."))
+	}
+	room := max(w-62, 10) // what is left for the message after the fixed columns
+	for i := len(e.log) - 1; i >= 0 && i >= len(e.log)-rows; i-- {
+		ev := e.log[i]
+		mark, msg := warn.Render("⚠"), truncate(ev.Message, room)
+		if ev.Cleared {
+			mark, msg = ok.Render("✓"), dim.Render("cleared")
+		}
+		b.WriteString(fmt.Sprintf("\n  %s %s %-8s %-16s %-14s %s",
+			dim.Render(ev.Time.Format("15:04:05")), mark, ev.AgentID, truncate(ev.Name, 16), ev.Kind, msg))
+	}
+
+	return lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(th.Border).
+		Padding(0, 1).Width(w - 4).
+		Render(b.String())
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// firing lists an agent's firing rules in rule order.
+func firing(e *alertEngine, id string) string {
+	var kinds []string
+	for _, al := range e.alertsFor(id) {
+		kinds = append(kinds, al.Kind.String())
+	}
+	return strings.Join(kinds, ", ")
+}
+
+// tokHistory is a history holding the given tok/s samples.
+func tokHistory(samples ...float64) *agentHistory {
+	h := &agentHistory{}
+	for _, v := range samples {
+		h.tokRate.push(v)
+	}
+	return h
+}
+
+func TestAlertRules(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	// Opus's floor is 25 tok/s; at the default ratio of 0.5 "slow" is under 12.5
+	base := Agent{ID: "pai-1a2b", Name: "Engineer", Model: "claude-opus-4-6", Status: StatusRunning,
+		Phase: PhaseBuild, LastActTime: now, ISCItems: []ISCCriterion{{Text: "tests pass", Passed: true}}}
+	errorAt := func(e *alertEngine, a Agent, at time.Time) {
+		prev := a
+		prev.Status = StatusRunning
+		a.Status = StatusError
+		e.observe(prev, a, at)
+	}
+
+	tests := []struct {
+		name    string
+		rules   func(r *AlertRules)
+		agent   func(a *Agent)
+		setup   func(e *alertEngine, a Agent) // transitions seen before now
+		history *agentHistory
+		want    string
+	}{
+		{name: "healthy", want: ""},
+
+		{name: "stalled", agent: func(a *Agent) { a.LastActTime = now.Add(-2 * time.Minute) }, want: "stalled"},
+		{name: "not stalled yet", agent: func(a *Agent) { a.LastActTime = now.Add(-time.Minute) }, want: ""},
+		{name: "stall rule off", rules: func(r *AlertRules) { r.StallAfter = 0 },
+			agent: func(a *Agent) { a.LastActTime = now.Add(-time.Hour) }, want: ""},
+		{name: "idle agents do not stall", agent: func(a *Agent) { a.Status, a.LastActTime = StatusIdle, now.Add(-time.Hour) }, want: ""},
+
+		{name: "phase stuck", setup: func(e *alertEngine, a Agent) { e.phaseSince[a.ID] = phaseMark{a.Phase, now.Add(-6 * time.Minute)} },
+			want: "phase stuck"},
+		{name: "phase changed since", setup: func(e *alertEngine, a Agent) { e.phaseSince[a.ID] = phaseMark{PhasePlan, now.Add(-6 * time.Minute)} },
+			want: ""},
+		{name: "done is never stuck", agent: func(a *Agent) { a.Phase = PhaseDone },
+			setup: func(e *alertEngine, a Agent) { e.phaseSince[a.ID] = phaseMark{a.Phase, now.Add(-time.Hour)} }, want: ""},
+
+		{name: "slow", history: tokHistory(40, 12, 10, 5), want: "slow"},
+		{name: "one fast sample", history: tokHistory(12, 13, 5), want: ""},
+		{name: "too few samples", history: tokHistory(5, 5), want: ""},
+		{name: "unknown model", agent: func(a *Agent) { a.Model = "mystery" }, history: tokHistory(0, 0, 0), want: ""},
+
+		{name: "error loop", agent: func(a *Agent) { a.Status = StatusError }, setup: func(e *alertEngine, a Agent) {
+			for _, ago := range []time.Duration{4 * time.Minute, 2 * time.Minute, 0} {
+				errorAt(e, a, now.Add(-ago))
+			}
+		}, want: "error loop"},
+		{name: "old errors age out", agent: func(a *Agent) { a.Status = StatusError }, setup: func(e *alertEngine, a Agent) {
+			for _, ago := range []time.Duration{6 * time.Minute, 2 * time.Minute, 0} {
+				errorAt(e, a, now.Add(-ago))
+			}
+		}, want: ""},
+
+		{name: "ISC regression", agent: func(a *Agent) { a.ISCItems = []ISCCriterion{{Text: "tests pass"}} },
+			setup: func(e *alertEngine, a Agent) { e.observe(base, a, now) }, want: "ISC regression"},
+		{name: "ISC regression off", rules: func(r *AlertRules) { r.ISCRegression = false },
+			agent: func(a *Agent) { a.ISCItems = []ISCCriterion{{Text: "tests pass"}} },
+			setup: func(e *alertEngine, a Agent) { e.observe(base, a, now) }, want: ""},
+		{name: "never passed", agent: func(a *Agent) { a.ISCItems = []ISCCriterion{{Text: "lint"}} },
+			setup: func(e *alertEngine, a Agent) { e.observe(base, a, now) }, want: ""},
+
+		{name: "several at once", agent: func(a *Agent) { a.LastActTime = now.Add(-2 * time.Minute) },
+			history: tokHistory(1, 1, 1), want: "stalled, slow"},
+	}
+	for _, tt := range tests {
+		rules := defaultAlertRules()
+		if tt.rules != nil {
+			tt.rules(&rules)
+		}
+		a := copyAgent(base)
+		if tt.agent != nil {
+			tt.agent(&a)
+		}
+		e := newAlertEngine(rules)
+		if tt.setup != nil {
+			tt.setup(e, a)
+		}
+		e.evaluate([]Agent{a}, map[string]*agentHistory{a.ID: tt.history}, now)
+		if got := firing(e, a.ID); got != tt.want {
+			t.Errorf("%s: firing %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAlertTransitions(t *testing.T) {
+	t0 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	e := newAlertEngine(defaultAlertRules())
+	a := Agent{ID: "pai-1a2b", Name: "Engineer", Status: StatusRunning, LastActTime: t0}
+	logged := func() string {
+		var out []string
+		for _, ev := range e.log {
+			state := "fired"
+			if ev.Cleared {
+				state = "cleared"
+			}
+			out = append(out, ev.Kind.String()+" "+state)
+		}
+		return strings.Join(out, ", ")
+	}
+
+	// The rule fires once, keeps its start time while it keeps firing, and
+	// is logged again only when it clears
+	e.evaluate([]Agent{a}, nil, t0.Add(2*time.Minute))
+	e.evaluate([]Agent{a}, nil, t0.Add(3*time.Minute))
+	if got := logged(); got != "stalled fired" {
+		t.Fatalf("log %q", got)
+	}
+	if al := e.alertsFor(a.ID); len(al) != 1 || !al[0].Since.Equal(t0.Add(2*time.Minute)) || e.count() != 1 {
+		t.Fatalf("firing %+v, count %d", al, e.count())
+	}
+	a.LastActTime = t0.Add(3 * time.Minute)
+	e.evaluate([]Agent{a}, nil, t0.Add(3*time.Minute))
+	if got := logged(); got != "stalled fired, stalled cleared" || e.count() != 0 {
+		t.Fatalf("log %q, count %d", got, e.count())
+	}
+
+	// Agents that are gone are forgotten without a log entry
+	a.LastActTime = t0
+	e.evaluate([]Agent{a}, nil, t0.Add(time.Hour))
+	n := len(e.log)
+	e.evaluate(nil, nil, t0.Add(time.Hour))
+	if e.count() != 0 || len(e.phaseSince) != 0 || len(e.log) != n {
+		t.Errorf("after removal: count %d, %d phase marks, log %q", e.count(), len(e.phaseSince), logged())
+	}
+}
+
+func TestLoadAlertRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"partial", `{"stall_after": "2m", "slow_samples": 5}`, ""},
+		{"bad json", `{"stall_after": `, "alert rules: parse"},
+		{"number for a duration", `{"stall_after": 90}`, "stall_after"},
+		{"negative", `{"error_loop_count": -1}`, "alerts.error_loop_count must not be negative"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "alerts.json")
+		if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		r, err := loadAlertRules(path)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error %v, want one containing %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		want := defaultAlertRules()
+		want.StallAfter, want.SlowSamples = duration(2*time.Minute), 5
+		if r != want {
+			t.Errorf("%s: %+v, want %+v", tt.name, r, want)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+// Answering agents — the input panel behind 'i' and the waiting queue
+// behind 'w'.
+//
+// An agent blocked on a human is Paused with a Question: what it asked and,
+// for multiple choice, the options it offered. Sources that can deliver a
+// reply implement Answerer; the agent resumes once it has its answer. The
+// queue lists every waiting agent, longest waiting first, so nobody is left
+// blocked behind a scrolled-off row.
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// AgentQuestion is what a paused agent is waiting to be told.
+type AgentQuestion struct {
+	Text    string
+	Options []string  // multiple-choice answers, none for free text
+	Asked   time.Time // when the agent asked
+}
+
+// Answerer is implemented by sources that can deliver an answer to an agent
+// awaiting input.
+type Answerer interface {
+	Answer(id, answer string) error
+}
+
+const maxAnswerLen = 500
+
+// errNotWaiting is returned by Answer for agents with no open question.
+var errNotWaiting = errors.New("not awaiting input")
+
+// waiting reports whether the agent is blocked on a question.
+func (a Agent) waiting() bool {
+	return a.Status == StatusPaused && a.Question.Text != ""
+}
+
+// waitingAgents returns the agents blocked on a question, longest waiting
+// first.
+func waitingAgents(agents []Agent) []Agent {
+	var out []Agent
+	for _, a := range agents {
+		if a.waiting() {
+			out = append(out, a)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Question.Asked.Before(out[j].Question.Asked)
+	})
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Form
+// ---------------------------------------------------------------------------
+
+// answerForm is the modal shown while answering. Like spawnForm it is a
+// value type owned by the model. The cursor picks one of the question's
+// options or, one past the last of them, the free-text line.
+type answerForm struct {
+	agent  Agent
+	choice int
+	input  textinput.Model
+	err    string
+}
+
+func newAnswerForm(a Agent) answerForm {
+	in := textinput.New()
+	in.Placeholder = "Type an answer"
+	in.CharLimit = maxAnswerLen
+	in.Cursor.SetMode(cursor.CursorStatic) // no blink messages to route
+	f := answerForm{agent: a, input: in}
+	f.setChoice(0)
+	return f
+}
+
+func (f *answerForm) setChoice(i int) {
+	n := len(f.agent.Question.Options) + 1
+	f.choice = (i + n) % n
+	if f.typing() {
+		f.input.Focus()
+	} else {
+		f.input.Blur()
+	}
+}
+
+// typing reports whether the free-text line is selected.
+func (f answerForm) typing() bool {
+	return f.choice == len(f.agent.Question.Options)
+}
+
+// answer is the selected option, or the typed text.
+func (f answerForm) answer() string {
+	if f.typing() {
+		return strings.TrimSpace(f.input.Value())
+	}
+	return f.agent.Question.Options[f.choice]
+}
+
+// Update handles a key press. Digits pick an option directly.
+func (f answerForm) Update(msg tea.KeyMsg) (answerForm, formResult, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		return f, formCancel, nil
+	case "enter":
+		if f.answer() == "" {
+			f.err = "answer is empty"
+			return f, formEditing, nil
+		}
+		return f, formSubmit, nil
+	case "tab", "down":
+		f.setChoice(f.choice + 1)
+		return f, formEditing, nil
+	case "shift+tab", "up":
+		f.setChoice(f.choice - 1)
+		return f, formEditing, nil
+	}
+	if !f.typing() {
+		if s := msg.String(); len(s) == 1 && s[0] >= '1' && int(s[0]-'1') < len(f.agent.Question.Options) {
+			f.setChoice(int(s[0] - '1'))
+		}
+		return f, formEditing, nil
+	}
+	var cmd tea.Cmd
+	f.input, cmd = f.input.Update(msg)
+	f.err = ""
+	return f, formEditing, cmd
+}
+
+func (f answerForm) View(w int, th Theme) string {
+	title := lipgloss.NewStyle().Bold(true).Foreground(th.Title)
+	dim := lipgloss.NewStyle().Foreground(th.Dim)
+	focused := lipgloss.NewStyle().Foreground(th.Accent).Bold(true)
+
+	boxW := min(w-4, 80)
+	q := f.agent.Question
+	var b strings.Builder
+	b.WriteString(title.Render(fmt.Sprintf("Answer %s (%s)", f.agent.ID, f.agent.Name)) +
+		dim.Render("  asked "+fmtAgo(q.Asked)) + "\n\n")
+	b.WriteString(lipgloss.NewStyle().Width(boxW-6).Render(q.Text) + "\n\n")
+
+	row := func(i int, text string) string {
+		if f.choice == i {
+			return focused.Render("▶ ") + text
+		}
+		return "  " + text
+	}
+	for i, o := range q.Options {
+		label := fmt.Sprintf("%d. %s", i+1, o)
+		if f.choice == i {
+			label = focused.Render(label)
+		}
+		b.WriteString(row(i, label) + "\n")
+	}
+	b.WriteString(row(len(q.Options), f.input.View()) + "\n\n")
+	if f.err != "" {
+		b.WriteString(lipgloss.NewStyle().Foreground(th.Error).Render("✗ "+f.err) + "\n")
+	}
+	hint := "⏎ send • esc cancel"
+	if len(q.Options) > 0 {
+		hint = "↑↓/1-9 choose • " + hint
+	}
+	b.WriteString(dim.Render(hint))
+
+	return lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(th.Accent).
+		Padding(1, 2).Width(boxW).
+		Render(b.String())
+}
+
+// ---------------------------------------------------------------------------
+// Model
+// ---------------------------------------------------------------------------
+
+// openAnswer opens the input panel for the selected agent or, when it is
+// not waiting, for the agent that has waited longest.
+func (m *model) openAnswer() {
+	if _, ok := m.source.(Answerer); !ok {
+		m.notice = "answer: " + errUnsupported.Error()
+		return
+	}
+	a, ok := m.selected()
+	if !ok || !a.waiting() {
+		queue := waitingAgents(m.agents)
+		if len(queue) == 0 {
+			m.notice = "no agents are awaiting input"
+			return
+		}
+		a = queue[0]
+		m.keepSelection(a.ID)
+	}
+	f := newAnswerForm(a)
+	m.answering = &f
+	m.notice = ""
+}
+
+// updateAnswer routes keys to the open input panel and delivers the answer.
+func (m model) updateAnswer(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f, res, cmd := m.answering.Update(msg)
+	switch res {
+	case formCancel:
+		m.answering = nil
+	case formSubmit:
+		if err := m.source.(Answerer).Answer(f.agent.ID, f.answer()); err != nil {
+			f.err = err.Error()
+			m.answering = &f
+			break
+		}
+		m.answering = nil
+		m.notice = fmt.Sprintf("answered %s", f.agent.ID)
+	default:
+		m.answering = &f
+	}
+	return m, cmd
+}
+
+// renderQueue lists the agents awaiting input, longest waiting first.
+func (m model) renderQueue(w, rows int) string {
+	title := lipgloss.NewStyle().Bold(true).Foreground(m.theme.Title)
+	dim := lipgloss.NewStyle().Foreground(m.theme.Dim)
+	paused := lipgloss.NewStyle().Foreground(m.theme.Paused)
+
+	queue := waitingAgents(m.agents)
+	var b strings.Builder
+	b.WriteString(title.Render("Awaiting Input") + dim.Render(fmt.Sprintf("  %d waiting  %s: answer",
+		len(queue), keys.Answer.Help().Key)))
+	if len(queue) == 0 {
+		b.WriteString("\n" + dim.Render("  No agents are waiting on you."))
+	}
+	cur, _ := m.selected()
+	room := max(w-40, 10) // what is left for the question after the fixed columns
+	for i, a := range queue {
+		if i == rows {
+			b.WriteString("\n" + dim.Render(fmt.Sprintf("  … %d more", len(queue)-rows)))
+			break
+		}
+		mark := "  "
+		if a.ID == cur.ID {
+			mark = "▸ "
+		}
+		q := a.Question.Text
+		if len(a.Question.Options) > 0 {
+			q += " [" + strings.Join(a.Question.Options, " / ") + "]"
+		}
+		b.WriteString(fmt.Sprintf("\n%s%-8s %-16s %s %s", mark, a.ID, truncate(a.Name, 16),
+			paused.Render(fmt.Sprintf("%6s", fmtDuration(time.Since(a.Question.Asked)))), truncate(oneLine(q), room)))
+	}
+
+	return lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(m.theme.Border).
+		Padding(0, 1).Width(w - 4).
+		Render(b.String())
+}
@@ -0,0 +1,398 @@
+package main
+
+// Configuration file — TOML, read from --config or the XDG config path
+// ($XDG_CONFIG_HOME/pai-tui/config.toml, else ~/.config/pai-tui/config.toml).
+//
+//	tick_interval = "2s"     # UI refresh and history sample rate
+//	load_delay    = "1.5s"   # splash screen before the first frame
+//	event_log_cap = 20       # events kept per agent
+//	theme = "auto"           # or a built-in palette, see theme.go
+//
+//	[colors]                 # theme overrides: "#rrggbb", "#rgb" or ANSI "0"-"255"
+//	title = "#7aa2f7"
+//
+//	[columns]                # agent table widths in cells
+//	name = 20
+//
+//	[keys]                   # remapped bindings, see keys.go
+//	pause = "P"
+//
+//	[restart]                # restart policies by agent name, see restart.go
+//	"*" = { policy = "on-failure", max_retries = 3 }
+//
+//	[prices]                 # token prices and budgets, see cost.go
+//	[alerts]                 # anomaly thresholds, see alerts.go
+//	[[notify.rules]]         # notifications, see notify.go
+//
+//	[source]                 # defaults for the matching command-line flags
+//	name        = "live"
+//	history_dir = "~/.claude/history/raw-outputs"
+//
+// Every key is optional. Flags given on the command line win over [source],
+// and the files given to --prices, --alert-rules and --notify replace
+// [prices], [alerts] and [[notify.rules]] for as long as the dashboard runs.
+// The file is watched while the dashboard runs: everything but [source]
+// applies immediately; [source] changes need a restart.
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	defaultTickInterval = 2 * time.Second
+	defaultLoadDelay    = 1500 * time.Millisecond
+	defaultEventLogCap  = 20
+	configPollInterval  = time.Second
+)
+
+// eventLogCap bounds every agent's EventLog. Sources read it from their own
+// goroutines while a config reload may change it, hence the atomic.
+var eventLogCap atomic.Int64
+
+func init() { eventLogCap.Store(defaultEventLogCap) }
+
+// Config is the decoded configuration file.
+type Config struct {
+	TickInterval duration                `toml:"tick_interval"`
+	LoadDelay    duration                `toml:"load_delay"`
+	EventLogCap  int                     `toml:"event_log_cap"`
+	Theme        string                  `toml:"theme"`
+	Colors       colorConfig             `toml:"colors"`
+	Columns      columnWidths            `toml:"columns"`
+	Source       sourceConfig            `toml:"source"`
+	Keys         map[string]keyList      `toml:"keys"`
+	Restart      map[string]restartEntry `toml:"restart"`
+	Prices       Pricing                 `toml:"prices"`
+	Alerts       AlertRules              `toml:"alerts"`
+	Notify       notifyConfig            `toml:"notify"`
+}
+
+type colorConfig struct {
+	Title   string `toml:"title"`
+	Running string `toml:"running"`
+	Idle    string `toml:"idle"`
+	Paused  string `toml:"paused"`
+	Error   string `toml:"error"`
+	Stopped string `toml:"stopped"`
+	Border  string `toml:"border"`
+	Fg      string `toml:"fg"`
+	Dim     string `toml:"dim"`
+	SelBg   string `toml:"sel_bg"`
+	Accent  string `toml:"accent"`
+	Bar     string `toml:"bar"`
+	BarBg   string `toml:"bar_bg"`
+}
+
+// columnWidths are the fixed agent-table columns; CURRENT PROCESS takes
+// whatever is left.
+type columnWidths struct {
+	ID       int `toml:"id"`
+	Name     int `toml:"name"`
+	Status   int `toml:"status"`
+	Phase    int `toml:"phase"`
+	Progress int `toml:"progress"`
+	Tok      int `toml:"tok"`
+	Trend    int `toml:"trend"`
+	Cost     int `toml:"cost"`
+	Uptime   int `toml:"uptime"`
+}
+
+var defaultColumns = columnWidths{ID: 11, Name: 16, Status: 9, Phase: 9, Progress: 16, Tok: 8, Trend: 12, Cost: 8, Uptime: 8}
+
+type sourceConfig struct {
+	Name        string   `toml:"name"`
+	HistoryDir  string   `toml:"history_dir"`
+	WorkDir     string   `toml:"work_dir"`
+	AgentCmd    string   `toml:"agent_cmd"`
+	StopGrace   duration `toml:"stop_grace"`
+	MetricsAddr string   `toml:"metrics_addr"`
+	EventLogDir string   `toml:"event_log_dir"`
+}
+
+// defaultConfigPath is the XDG location of the config file.
+func defaultConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "pai-tui", "config.toml")
+}
+
+func defaultConfig() Config {
+	return Config{
+		TickInterval: duration(defaultTickInterval),
+		LoadDelay:    duration(defaultLoadDelay),
+		EventLogCap:  defaultEventLogCap,
+		Theme:        themeAuto,
+		Columns:      defaultColumns,
+		Prices:       defaultPricing(), // [prices] entries are decoded into these maps
+		Alerts:       defaultAlertRules(),
+	}
+}
+
+// loadConfig reads path over the defaults. A missing file is not an error
+// unless required (the user named it with --config).
+func loadConfig(path string, required bool) (Config, error) {
+	c := defaultConfig()
+	if path == "" {
+		return c, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return c, nil
+	}
+	if err != nil {
+		return c, fmt.Errorf("config: %w", err)
+	}
+	md, err := toml.Decode(string(data), &c)
+	if err != nil {
+		return c, fmt.Errorf("config: %s: %w", path, err)
+	}
+	if un := md.Undecoded(); len(un) > 0 {
+		keys := make([]string, len(un))
+		for i, k := range un {
+			keys[i] = k.String()
+		}
+		return c, fmt.Errorf("config: %s: unknown key %s", path, strings.Join(keys, ", "))
+	}
+	if err := c.validate(); err != nil {
+		return c, fmt.Errorf("config: %s: %w", path, err)
+	}
+	c.Source.HistoryDir = expandHome(c.Source.HistoryDir)
+	c.Source.WorkDir = expandHome(c.Source.WorkDir)
+	c.Source.EventLogDir = expandHome(c.Source.EventLogDir)
+	return c, nil
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+func (c Config) validate() error {
+	if d := time.Duration(c.TickInterval); d < 100*time.Millisecond || d > time.Minute {
+		return fmt.Errorf("tick_interval %s: must be between 100ms and 1m", d)
+	}
+	if d := time.Duration(c.LoadDelay); d < 0 || d > 10*time.Second {
+		return fmt.Errorf("load_delay %s: must be between 0s and 10s", d)
+	}
+	if c.EventLogCap < 1 || c.EventLogCap > 10000 {
+		return fmt.Errorf("event_log_cap %d: must be between 1 and 10000", c.EventLogCap)
+	}
+	if c.Theme != themeAuto && themeIndex(c.Theme) < 0 {
+		return fmt.Errorf("theme %q: want one of %s", c.Theme, strings.Join(themeNames(), ", "))
+	}
+	if err := checkKeys(c.Keys); err != nil {
+		return err
+	}
+	if err := checkRestart(c.Restart); err != nil {
+		return err
+	}
+	if err := c.Prices.check(); err != nil {
+		return err
+	}
+	if err := c.Alerts.check(); err != nil {
+		return err
+	}
+	if err := checkNotifyRules(c.Notify.Rules); err != nil {
+		return err
+	}
+	colors := c.Colors.byName()
+	for _, name := range sortedKeys(colors) {
+		v := colors[name]
+		if v == "" {
+			continue
+		}
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 && n <= 255 {
+			continue
+		}
+		if !hexColor.MatchString(v) {
+			return fmt.Errorf("colors.%s %q: want \"#rrggbb\", \"#rgb\" or an ANSI index 0-255", name, v)
+		}
+	}
+	widths := c.Columns.byName()
+	for _, name := range sortedKeys(widths) {
+		w := widths[name]
+		if w < 4 || w > 80 {
+			return fmt.Errorf("columns.%s %d: must be between 4 and 80", name, w)
+		}
+	}
+	// A replay needs a recording, which only --replay names
+	names := slices.DeleteFunc(slices.Clone(sourceNames), func(n string) bool { return n == "replay" })
+	if s := c.Source.Name; s == "replay" {
+		return errors.New(`source.name "replay": [source] has no recording to play; use --replay FILE`)
+	} else if s != "" && !slices.Contains(names, s) {
+		return fmt.Errorf("source.name %q: want one of %s", s, strings.Join(names, ", "))
+	}
+	if c.Source.StopGrace < 0 {
+		return fmt.Errorf("source.stop_grace must not be negative")
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (cc colorConfig) byName() map[string]string {
+	return map[string]string{
+		"title": cc.Title, "running": cc.Running, "idle": cc.Idle, "paused": cc.Paused,
+		"error": cc.Error, "stopped": cc.Stopped, "border": cc.Border, "fg": cc.Fg,
+		"dim": cc.Dim, "sel_bg": cc.SelBg, "accent": cc.Accent, "bar": cc.Bar, "bar_bg": cc.BarBg,
+	}
+}
+
+func (cw columnWidths) byName() map[string]int {
+	return map[string]int{
+		"id": cw.ID, "name": cw.Name, "status": cw.Status, "phase": cw.Phase, "progress": cw.Progress,
+		"tok": cw.Tok, "trend": cw.Trend, "cost": cw.Cost, "uptime": cw.Uptime,
+	}
+}
+
+// sum is the width of all fixed columns.
+func (cw columnWidths) sum() int {
+	return cw.ID + cw.Name + cw.Status + cw.Phase + cw.Progress + cw.Tok + cw.Trend + cw.Cost + cw.Uptime
+}
+
+// applyConfig installs the hot-reloadable settings on the model. A theme
+// picked at runtime survives a reload unless the file's theme key changed.
+func (m *model) applyConfig(c Config) {
+	if m.pricesFlag != nil {
+		c.Prices = *m.pricesFlag
+	}
+	if m.alertsFlag != nil {
+		c.Alerts = *m.alertsFlag
+	}
+	if m.notifyFlag != nil {
+		c.Notify = *m.notifyFlag
+	}
+	if c.Theme != m.config.Theme {
+		m.themePick = ""
+	}
+	m.config = c
+	if i := themeIndex(m.themePick); i >= 0 {
+		m.setTheme(themes[i])
+	} else {
+		m.setTheme(resolveTheme(c.Theme, m.darkBg))
+	}
+	applyKeys(c.Keys)
+	eventLogCap.Store(int64(c.EventLogCap))
+	m.pricing = c.Prices
+	m.alerts.rules = c.Alerts
+	m.notify.rules = c.Notify.Rules
+}
+
+// setTheme switches palettes, keeping the config's color overrides unless
+// NO_COLOR is in force.
+func (m *model) setTheme(t Theme) {
+	m.theme = t
+	if !noColor() || m.themePick != "" {
+		m.theme = t.withColors(m.config.Colors)
+	}
+	m.spinner.Style = lipgloss.NewStyle().Foreground(m.theme.Title)
+}
+
+// sourceFlags applies [source] to every flag not set on the command line.
+func (c Config) sourceFlags(set func(name, value string)) {
+	s := c.Source
+	for flag, v := range map[string]string{
+		"source": s.Name, "history-dir": s.HistoryDir, "work-dir": s.WorkDir, "agent-cmd": s.AgentCmd,
+		"metrics-addr": s.MetricsAddr, "event-log-dir": s.EventLogDir,
+	} {
+		if v != "" {
+			set(flag, v)
+		}
+	}
+	if s.StopGrace > 0 {
+		set("stop-grace", time.Duration(s.StopGrace).String())
+	}
+}
+
+// duration is a time.Duration that reads as "90s" in the config file and in
+// the JSON files given to --alert-rules and --notify.
+type duration time.Duration
+
+func (d *duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+func expandHome(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, p[1:])
+		}
+	}
+	return p
+}
+
+// ---------------------------------------------------------------------------
+// Hot reload
+// ---------------------------------------------------------------------------
+
+// configMsg carries a reloaded config, or why the reload failed.
+type configMsg struct {
+	cfg  Config
+	err  error
+	info os.FileInfo
+}
+
+// watchConfig polls the config file and reports when it changes. The
+// previous stat is threaded through the command so the model stays a value.
+func watchConfig(path string, prev os.FileInfo) tea.Cmd {
+	if path == "" {
+		return nil
+	}
+	return tea.Tick(configPollInterval, func(time.Time) tea.Msg {
+		info, err := os.Stat(path)
+		if err != nil || (prev != nil && info.ModTime().Equal(prev.ModTime()) && info.Size() == prev.Size()) {
+			return configPollMsg{info}
+		}
+		cfg, err := loadConfig(path, true)
+		return configMsg{cfg: cfg, err: err, info: info}
+	})
+}
+
+// configPollMsg re-arms the watcher when nothing changed.
+type configPollMsg struct{ info os.FileInfo }
+
+// sourceChanged reports which [source] keys differ, for the restart notice.
+func sourceChanged(a, b sourceConfig) []string {
+	var out []string
+	for k, v := range map[string][2]string{
+		"name": {a.Name, b.Name}, "history_dir": {a.HistoryDir, b.HistoryDir}, "work_dir": {a.WorkDir, b.WorkDir},
+		"agent_cmd": {a.AgentCmd, b.AgentCmd}, "metrics_addr": {a.MetricsAddr, b.MetricsAddr},
+		"event_log_dir": {a.EventLogDir, b.EventLogDir},
+		"stop_grace":    {time.Duration(a.StopGrace).String(), time.Duration(b.StopGrace).String()},
+	} {
+		if v[0] != v[1] {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
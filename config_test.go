@@ -0,0 +1,261 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig writes a config file and returns its path.
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfigValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string // "" when the file is valid
+	}{
+		{"empty", ``, ""},
+		{"everything set", `
+tick_interval = "1s"
+load_delay    = "0s"
+event_log_cap = 100
+theme         = "catppuccin"
+[colors]
+title = "#abc"
+bar   = "208"
+[columns]
+name = 20
+[source]
+name       = "live"
+stop_grace = "10s"
+[prices.models]
+my-model = { input = 1, output = 2 }
+[alerts]
+stall_after = "0s"
+[[notify.rules]]
+bell = true
+`, ""},
+
+		{"bad syntax", `tick_interval = `, "config.toml"},
+		{"unknown key", `tick = "1s"`, "unknown key tick"},
+		{"unknown nested key", "[columns]\nwidth = 3", "unknown key columns.width"},
+		{"wrong type", `event_log_cap = "lots"`, "event_log_cap"},
+		{"bad duration", `tick_interval = "soon"`, "soon"},
+		{"tick too fast", `tick_interval = "10ms"`, "tick_interval 10ms: must be between 100ms and 1m"},
+		{"load delay too long", `load_delay = "1m"`, "load_delay 1m0s: must be between 0s and 10s"},
+		{"event log cap", `event_log_cap = 0`, "event_log_cap 0: must be between 1 and 10000"},
+		{"unknown theme", `theme = "neon"`, `theme "neon": want one of`},
+		{"bad color", "[colors]\ntitle = \"blue\"", `colors.title "blue"`},
+		{"color index", "[colors]\ntitle = \"256\"", `colors.title "256"`},
+		{"narrow column", "[columns]\ntok = 2", "columns.tok 2: must be between 4 and 80"},
+		{"unknown source", "[source]\nname = \"kafka\"", `source.name "kafka": want one of`},
+		{"replay source", "[source]\nname = \"replay\"", `source.name "replay": [source] has no recording to play; use --replay FILE`},
+		{"negative grace", "[source]\nstop_grace = \"-1s\"", "source.stop_grace must not be negative"},
+		{"old prices path", "[source]\nprices = \"prices.json\"", "unknown key source.prices"},
+
+		{"negative price", "[prices.models]\nx = { input = -1 }", "prices.models.x: prices must not be negative"},
+		{"zero budget", "[prices.budgets]\nEngineer = 0", "prices.budgets.Engineer 0: must be positive"},
+		{"negative threshold", "[alerts]\nerror_loop_count = -1", "alerts.error_loop_count must not be negative"},
+		{"unknown event", "[[notify.rules]]\nevents = [\"crash\"]\nbell = true", `notify rule 1: unknown event "crash"`},
+		{"no delivery", "[[notify.rules]]\nbell = true\n[[notify.rules]]\nevents = [\"done\"]", "notify rule 2: set at least one of"},
+		{"bad webhook", "[[notify.rules]]\nwebhook = \"ftp://host/x\"", "notify rule 1: webhook must be an http(s) URL"},
+		{"bad desktop", "[[notify.rules]]\ndesktop = \"growl\"", `notify rule 1: desktop must be "osc9" or "osc777"`},
+		{"negative interval", "[[notify.rules]]\nbell = true\nmin_interval = \"-1m\"", "notify rule 1: min_interval must not be negative"},
+
+		{"restart policies", `
+[restart]
+"*"      = { policy = "on-failure", max_retries = 5, backoff = "10s" }
+Engineer = { max_retries = 0 }
+Intern   = { policy = "always", max_backoff = "1m", breaker_failures = 1 }
+`, ""},
+		{"unknown policy", "[restart]\nEngineer = { policy = \"sometimes\" }", `restart.Engineer.policy "sometimes": want never, on-failure or always`},
+		{"too many retries", "[restart]\n\"*\" = { max_retries = 101 }", "restart.*.max_retries 101: must be between 0 and 100"},
+		{"no breaker", "[restart]\nEngineer = { breaker_failures = 0 }", "restart.Engineer.breaker_failures 0: must be between 1 and 1000"},
+		{"negative backoff", "[restart]\nEngineer = { backoff = \"-1s\" }", "restart.Engineer: durations must not be negative"},
+		{"backoff over max", "[restart]\nEngineer = { backoff = \"10m\" }", "restart.Engineer: max_backoff 5m0s is less than backoff 10m0s"},
+		{"inherited backoff over max", "[restart]\n\"*\" = { backoff = \"1m\" }\nEngineer = { max_backoff = \"30s\" }",
+			"restart.Engineer: max_backoff 30s is less than backoff 1m0s"},
+	}
+	for _, tt := range tests {
+		_, err := loadConfig(writeConfig(t, tt.data), true)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: %v", tt.name, err)
+		case tt.wantErr != "" && err == nil:
+			t.Errorf("%s: no error, want one containing %q", tt.name, tt.wantErr)
+		case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+			t.Errorf("%s: error %q, want one containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestConfigKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"one key", `pause = "P"`, ""},
+		{"list", `up = ["k", "up", "ctrl+p"]`, ""},
+		{"named keys", `detail = ["enter", "f5", "alt+x"]`, ""},
+		{"space", `detail = "space"`, `keys.detail: " " is already bound to play`},
+		{"swapped", "pause = \"r\"\nrefresh = \"p\"", ""},
+		{"taken from a default", `pause = "r"`, `keys.pause: "r" is already bound to refresh`},
+		{"taken from a default, later action", `tree = "a"`, `keys.tree: "a" is already bound to alerts`},
+		{"two remaps", "pause = \"x\"\nrefresh = \"x\"", `"x" is already bound to`},
+		{"twice in one list", `pause = ["x", "x"]`, `keys.pause: "x" is already bound to pause`},
+		{"digit", `pause = "5"`, `keys.pause: "5" is reserved for count prefixes`},
+		{"not a key", `pause = "ctrl+1"`, `keys.pause: "ctrl+1" is not a key`},
+		{"word", `pause = "pause"`, `keys.pause: "pause" is not a key`},
+		{"empty list", `pause = []`, "keys.pause: no keys given"},
+		{"unknown action", `jump = "J"`, "keys.jump: unknown action"},
+		{"wrong type", `pause = 5`, "want a key name or a list of key names"},
+	}
+	for _, tt := range tests {
+		_, err := loadConfig(writeConfig(t, "[keys]\n"+tt.data), true)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: %v", tt.name, err)
+		case tt.wantErr != "" && err == nil:
+			t.Errorf("%s: no error, want one containing %q", tt.name, tt.wantErr)
+		case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+			t.Errorf("%s: error %q, want one containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestConfigDefaults(t *testing.T) {
+	c, err := loadConfig(writeConfig(t, `
+tick_interval = "1s"
+[columns]
+name = 20
+[prices.models]
+claude-opus-4-6 = { input = 6, output = 30 }
+[alerts]
+stall_after = "2m"
+`), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Duration(c.TickInterval) != time.Second || time.Duration(c.LoadDelay) != defaultLoadDelay {
+		t.Errorf("tick %v, load delay %v", time.Duration(c.TickInterval), time.Duration(c.LoadDelay))
+	}
+	if c.Columns.Name != 20 || c.Columns.ID != defaultColumns.ID {
+		t.Errorf("columns %+v", c.Columns)
+	}
+	// [prices] overrides one model and keeps the built-in rest
+	if p := c.Prices.Models["claude-opus-4-6"]; p.Input != 6 || p.Output != 30 || p.Cached != 0 {
+		t.Errorf("overridden price %+v", p)
+	}
+	if p := c.Prices.Models["grok-3"]; p != defaultPrices["grok-3"] {
+		t.Errorf("built-in price %+v, want %+v", p, defaultPrices["grok-3"])
+	}
+	want := defaultAlertRules()
+	want.StallAfter = duration(2 * time.Minute)
+	if c.Alerts != want {
+		t.Errorf("alerts %+v, want %+v", c.Alerts, want)
+	}
+	// The defaults themselves are left alone
+	if defaultPricing().Models["claude-opus-4-6"].Input != 5 {
+		t.Error("loading a config changed the built-in prices")
+	}
+
+	// A missing file is fine unless it was asked for
+	missing := filepath.Join(t.TempDir(), "nope.toml")
+	if _, err := loadConfig(missing, false); err != nil {
+		t.Errorf("missing default config: %v", err)
+	}
+	if _, err := loadConfig(missing, true); err == nil {
+		t.Error("missing --config file: no error")
+	}
+}
+
+func TestApplyConfigFlagFiles(t *testing.T) {
+	c, err := loadConfig(writeConfig(t, `
+[prices.budgets]
+default = 1
+[alerts]
+stall_after = "2m"
+[[notify.rules]]
+bell = true
+`), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newModel(newSimulatedSource(1))
+	m.applyConfig(c)
+	if m.pricing.Budgets["default"] != 1 || time.Duration(m.alerts.rules.StallAfter) != 2*time.Minute || len(m.notify.rules) != 1 {
+		t.Fatalf("tables not applied: budgets %v, alerts %+v, %d notify rules", m.pricing.Budgets, m.alerts.rules, len(m.notify.rules))
+	}
+
+	// Files given on the command line replace the tables, on every reload
+	prices, alerts := defaultPricing(), defaultAlertRules()
+	m.pricesFlag, m.alertsFlag, m.notifyFlag = &prices, &alerts, &notifyConfig{}
+	for i := 0; i < 2; i++ {
+		m.applyConfig(c)
+		if len(m.pricing.Budgets) != 0 || m.alerts.rules != alerts || len(m.notify.rules) != 0 {
+			t.Errorf("reload %d: budgets %v, alerts %+v, %d notify rules", i, m.pricing.Budgets, m.alerts.rules, len(m.notify.rules))
+		}
+	}
+}
+
+func TestNoColorTheme(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	c, err := loadConfig(writeConfig(t, "theme = \"catppuccin\"\n[colors]\ntitle = \"#ff0000\""), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newModel(newSimulatedSource(1))
+	m.applyConfig(c)
+	if m.theme.Name != "monochrome" || m.theme.Title != themes[themeIndex("monochrome")].Title {
+		t.Errorf("NO_COLOR with a configured theme: %s, title %v", m.theme.Name, m.theme.Title)
+	}
+
+	// A theme picked with T survives reloads
+	m.themePick = "catppuccin"
+	m.applyConfig(c)
+	if m.theme.Name != "catppuccin" {
+		t.Errorf("runtime pick under NO_COLOR: %s", m.theme.Name)
+	}
+}
+
+func TestRestartInheritance(t *testing.T) {
+	c, err := loadConfig(writeConfig(t, `
+[restart]
+"*"      = { policy = "on-failure", max_retries = 5, backoff = "10s" }
+Engineer = { max_retries = 0 }
+Intern   = { policy = "always", breaker_failures = 1 }
+`), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		want restartPolicy
+	}{
+		{"Engineer", restartPolicy{Policy: policyOnFailure, MaxRetries: 0, Backoff: duration(10 * time.Second),
+			MaxBackoff: defaultRestart.MaxBackoff, BreakerFailures: defaultRestart.BreakerFailures, BreakerWindow: defaultRestart.BreakerWindow}},
+		{"Intern", restartPolicy{Policy: policyAlways, MaxRetries: 5, Backoff: duration(10 * time.Second),
+			MaxBackoff: defaultRestart.MaxBackoff, BreakerFailures: 1, BreakerWindow: defaultRestart.BreakerWindow}},
+		{"Architect", restartPolicy{Policy: policyOnFailure, MaxRetries: 5, Backoff: duration(10 * time.Second),
+			MaxBackoff: defaultRestart.MaxBackoff, BreakerFailures: defaultRestart.BreakerFailures, BreakerWindow: defaultRestart.BreakerWindow}},
+	}
+	for _, tt := range tests {
+		if got := restartFor(c.Restart, tt.name); got != tt.want {
+			t.Errorf("%s: %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+	if got := restartFor(nil, "Engineer"); got != defaultRestart || got.Policy != policyNever {
+		t.Errorf("no [restart] table: %+v, want the defaults", got)
+	}
+}
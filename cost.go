@@ -0,0 +1,146 @@
+package main
+
+// Cost accounting — per-model token prices, per-agent spend and budgets.
+//
+// Prices are USD per million tokens. The built-in table covers the models
+// the dashboard knows about; the config file's [prices] table overrides or
+// extends it and sets per-agent budgets:
+//
+//	[prices]
+//	pause_over_budget = true
+//
+//	[prices.models]
+//	claude-opus-4-6 = { input = 5, output = 25, cached = 0.5 }
+//
+//	[prices.budgets]
+//	default  = 2.50
+//	Engineer = 5
+//	pai-1a2b = 1
+//
+// --prices FILE reads the same settings from JSON instead and wins over the
+// table:
+//
+//	{
+//	  "models":  {"claude-opus-4-6": {"input": 5, "output": 25, "cached": 0.5}},
+//	  "budgets": {"default": 2.50, "Engineer": 5, "pai-1a2b": 1},
+//	  "pause_over_budget": true
+//	}
+//
+// Budget keys are matched by agent ID first, then agent name, then "default".
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ModelPrice is the USD cost per million tokens for one model.
+type ModelPrice struct {
+	Input  float64 `toml:"input" json:"input"`
+	Output float64 `toml:"output" json:"output"`
+	Cached float64 `toml:"cached" json:"cached"`
+}
+
+// Pricing holds the price table and budget rules.
+type Pricing struct {
+	Models          map[string]ModelPrice `toml:"models" json:"models"`
+	Budgets         map[string]float64    `toml:"budgets" json:"budgets"`
+	PauseOverBudget bool                  `toml:"pause_over_budget" json:"pause_over_budget"`
+}
+
+var defaultPrices = map[string]ModelPrice{
+	"claude-opus-4-6":   {Input: 5, Output: 25, Cached: 0.50},
+	"claude-sonnet-4-5": {Input: 3, Output: 15, Cached: 0.30},
+	"claude-haiku-4-5":  {Input: 1, Output: 5, Cached: 0.10},
+	"gemini-2.5-pro":    {Input: 1.25, Output: 10, Cached: 0.31},
+	"grok-3":            {Input: 3, Output: 15, Cached: 0.75},
+}
+
+func defaultPricing() Pricing {
+	p := Pricing{Models: map[string]ModelPrice{}, Budgets: map[string]float64{}}
+	for m, price := range defaultPrices {
+		p.Models[m] = price
+	}
+	return p
+}
+
+// loadPricing reads a --prices file on top of the defaults.
+func loadPricing(path string) (Pricing, error) {
+	p := defaultPricing()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return p, fmt.Errorf("prices: %w", err)
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return p, fmt.Errorf("prices: parse %s: %w", path, err)
+	}
+	if err := p.check(); err != nil {
+		return p, fmt.Errorf("prices: %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// check rejects negative prices and budgets that are not positive.
+func (p Pricing) check() error {
+	for _, m := range sortedKeys(p.Models) {
+		if price := p.Models[m]; price.Input < 0 || price.Output < 0 || price.Cached < 0 {
+			return fmt.Errorf("prices.models.%s: prices must not be negative", m)
+		}
+	}
+	for _, k := range sortedKeys(p.Budgets) {
+		if b := p.Budgets[k]; b <= 0 {
+			return fmt.Errorf("prices.budgets.%s %v: must be positive", k, b)
+		}
+	}
+	return nil
+}
+
+// Cost is the agent's total spend so far in USD.
+func (p Pricing) Cost(a Agent) float64 {
+	price := p.Models[a.Model]
+	return (float64(a.TotalTokensIn)*price.Input +
+		float64(a.TotalTokensOut)*price.Output +
+		float64(a.TotalTokensCached)*price.Cached) / 1e6
+}
+
+// CostRate estimates current spend in USD per minute from output throughput,
+// assuming input and cached tokens keep their historical ratio to output.
+func (p Pricing) CostRate(a Agent) float64 {
+	if a.TokensPerSec <= 0 || a.TotalTokensOut == 0 {
+		return 0
+	}
+	price := p.Models[a.Model]
+	out := float64(a.TotalTokensOut)
+	perOut := price.Output +
+		price.Input*float64(a.TotalTokensIn)/out +
+		price.Cached*float64(a.TotalTokensCached)/out
+	return a.TokensPerSec * 60 * perOut / 1e6
+}
+
+// Budget returns the agent's spend limit, or 0 when none applies.
+func (p Pricing) Budget(a Agent) float64 {
+	if b, ok := p.Budgets[a.ID]; ok {
+		return b
+	}
+	if b, ok := p.Budgets[a.Name]; ok {
+		return b
+	}
+	return p.Budgets["default"]
+}
+
+// OverBudget reports whether the agent has spent past its budget.
+func (p Pricing) OverBudget(a Agent) bool {
+	b := p.Budget(a)
+	return b > 0 && p.Cost(a) > b
+}
+
+func fmtCost(usd float64) string {
+	switch {
+	case usd >= 100:
+		return fmt.Sprintf("$%.0f", usd)
+	case usd >= 0.995:
+		return fmt.Sprintf("$%.2f", usd)
+	default:
+		return fmt.Sprintf("$%.3f", usd)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPricingCost(t *testing.T) {
+	p := Pricing{Models: map[string]ModelPrice{"m": {Input: 3, Output: 15, Cached: 0.3}}}
+	tests := []struct {
+		name  string
+		agent Agent
+		cost  float64 // USD
+		rate  float64 // USD per minute
+	}{
+		{"nothing used", Agent{Model: "m"}, 0, 0},
+		{"input only", Agent{Model: "m", TotalTokensIn: 1e6}, 3, 0}, // no output to estimate a rate from
+		{"all kinds", Agent{Model: "m", TotalTokensIn: 2e6, TotalTokensOut: 1e5, TotalTokensCached: 1e6}, 6 + 1.5 + 0.3, 0},
+		// 100 tok/s of output with 2 input and 1 cached token per output
+		// token: 6000 out/min at $15 + 12000 in at $3 + 6000 cached at $0.3
+		{"running", Agent{Model: "m", TotalTokensIn: 2e5, TotalTokensOut: 1e5, TotalTokensCached: 1e5, TokensPerSec: 100},
+			0.6 + 1.5 + 0.03, 0.09 + 0.036 + 0.0018},
+		{"unknown model", Agent{Model: "other", TotalTokensIn: 1e6, TotalTokensOut: 1e6, TokensPerSec: 100}, 0, 0}, // priced at zero
+	}
+	for _, tt := range tests {
+		if got := p.Cost(tt.agent); math.Abs(got-tt.cost) > 1e-9 {
+			t.Errorf("%s: cost %v, want %v", tt.name, got, tt.cost)
+		}
+		if got := p.CostRate(tt.agent); math.Abs(got-tt.rate) > 1e-9 {
+			t.Errorf("%s: rate %v, want %v", tt.name, got, tt.rate)
+		}
+	}
+}
+
+func TestPricingBudget(t *testing.T) {
+	p := Pricing{
+		Models:  map[string]ModelPrice{"m": {Output: 10}},
+		Budgets: map[string]float64{"default": 1, "Engineer": 5, "pai-1a2b": 2},
+	}
+	tests := []struct {
+		name   string
+		agent  Agent
+		budget float64
+		over   bool
+	}{
+		{"by ID", Agent{ID: "pai-1a2b", Name: "Engineer", Model: "m", TotalTokensOut: 3e5}, 2, true},
+		{"by name", Agent{ID: "pai-3c4d", Name: "Engineer", Model: "m", TotalTokensOut: 3e5}, 5, false},
+		{"default", Agent{ID: "pai-5e6f", Name: "Intern", Model: "m", TotalTokensOut: 1e5}, 1, false},
+		{"over default", Agent{ID: "pai-5e6f", Name: "Intern", Model: "m", TotalTokensOut: 2e5}, 1, true},
+		{"just over it", Agent{ID: "pai-5e6f", Name: "Intern", Model: "m", TotalTokensOut: 1e5 + 1}, 1, true},
+	}
+	for _, tt := range tests {
+		if got := p.Budget(tt.agent); got != tt.budget {
+			t.Errorf("%s: budget %v, want %v", tt.name, got, tt.budget)
+		}
+		if got := p.OverBudget(tt.agent); got != tt.over {
+			t.Errorf("%s: over budget %v, want %v", tt.name, got, tt.over)
+		}
+	}
+
+	// Without a default, agents with no entry have no budget
+	delete(p.Budgets, "default")
+	a := Agent{ID: "pai-5e6f", Name: "Intern", Model: "m", TotalTokensOut: 1e9}
+	if p.Budget(a) != 0 || p.OverBudget(a) {
+		t.Errorf("no budget: %v, over %v", p.Budget(a), p.OverBudget(a))
+	}
+}
+
+// controlSource records the control actions sent to it.
+type controlSource struct {
+	AgentSource
+	agents []Agent
+	sent   []string
+}
+
+func (s *controlSource) Snapshot() []Agent { return s.agents }
+
+func (s *controlSource) Control(id string, action ControlAction) error {
+	s.sent = append(s.sent, action.String()+" "+id)
+	return nil
+}
+
+func TestEnforceBudgets(t *testing.T) {
+	over := Agent{ID: "pai-1a2b", Model: "m", Status: StatusRunning, TotalTokensOut: 2e6}
+	under := Agent{ID: "pai-3c4d", Model: "m", Status: StatusRunning, TotalTokensOut: 1}
+	idle := Agent{ID: "pai-5e6f", Model: "m", Status: StatusIdle, TotalTokensOut: 2e6}
+	src := &controlSource{agents: []Agent{over, under, idle}}
+	m := newModel(src)
+	m.pricing = Pricing{Models: map[string]ModelPrice{"m": {Output: 1}}, Budgets: map[string]float64{"default": 1}}
+
+	m.enforceBudgets()
+	if len(src.sent) != 0 {
+		t.Fatalf("paused without pause_over_budget: %v", src.sent)
+	}
+
+	m.pricing.PauseOverBudget = true
+	m.enforceBudgets()
+	if want := []string{ActionPause.String() + " pai-1a2b"}; strings.Join(src.sent, ",") != strings.Join(want, ",") {
+		t.Fatalf("sent %v, want %v", src.sent, want)
+	}
+	if !strings.Contains(m.notice, "pai-1a2b paused: over $1.00 budget") {
+		t.Errorf("notice %q", m.notice)
+	}
+
+	// An agent is only paused once, even if it is resumed over budget
+	m.enforceBudgets()
+	if len(src.sent) != 1 {
+		t.Errorf("paused twice: %v", src.sent)
+	}
+}
+
+func TestLoadPricing(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"override and extend", `{"models": {"claude-opus-4-6": {"input": 6, "output": 30}, "mine": {"output": 1}}, "budgets": {"default": 2}, "pause_over_budget": true}`, ""},
+		{"bad json", `{"models": `, "prices: parse"},
+		{"negative price", `{"models": {"mine": {"input": -1}}}`, "prices.models.mine: prices must not be negative"},
+		{"zero budget", `{"budgets": {"Engineer": 0}}`, "prices.budgets.Engineer 0: must be positive"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "prices.json")
+		if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		p, err := loadPricing(path)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error %v, want one containing %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if p.Models["claude-opus-4-6"].Input != 6 || p.Models["mine"].Output != 1 || p.Models["grok-3"] != defaultPrices["grok-3"] {
+			t.Errorf("%s: models %v", tt.name, p.Models)
+		}
+		if p.Budgets["default"] != 2 || !p.PauseOverBudget {
+			t.Errorf("%s: budgets %v, pause %v", tt.name, p.Budgets, p.PauseOverBudget)
+		}
+	}
+}
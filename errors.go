@@ -0,0 +1,98 @@
+package main
+
+// Error diagnostics — why an agent is in StatusError.
+//
+// Sources record each failure as an AgentError on the agent: the message,
+// the tool or process that failed, its exit code and the tail of its
+// standard error. The last errorHistoryCap of them are kept, so an agent
+// that keeps failing shows every recent attempt, each numbered by how many
+// times the agent had been retried since it last finished cleanly.
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	errorHistoryCap = 10 // failures kept per agent
+	stderrTailLines = 6  // standard error lines kept per failure
+)
+
+// AgentError is one failure as the source saw it.
+type AgentError struct {
+	Message  string
+	Tool     string    // tool call that failed, "" when the failure was not a tool call
+	ExitCode int       // process exit code, 0 when it was not a process exit
+	Stderr   []string  // last lines of standard error, oldest first
+	Time     time.Time // when the failure happened
+	Retries  int       // the agent's retry count when it failed
+}
+
+// addError appends e to the agent's error history, stamped with its current
+// retry count, and drops the oldest entries past errorHistoryCap.
+func addError(a *Agent, e AgentError) {
+	e.Retries = a.Retries
+	a.Errors = append(a.Errors, e)
+	if n := len(a.Errors); n > errorHistoryCap {
+		a.Errors = append([]AgentError(nil), a.Errors[n-errorHistoryCap:]...)
+	}
+}
+
+// mergeErrors combines two error histories of one agent by time, keeping
+// the most recent errorHistoryCap.
+func mergeErrors(a, b []AgentError) []AgentError {
+	if len(b) == 0 {
+		return append([]AgentError(nil), a...)
+	}
+	out := make([]AgentError, 0, len(a)+len(b))
+	out = append(append(out, a...), b...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	if n := len(out); n > errorHistoryCap {
+		out = out[n-errorHistoryCap:]
+	}
+	return out
+}
+
+// trackRetry updates the retry count after a status change: a failed agent
+// going back to work is one more retry, and a clean finish clears it.
+func trackRetry(a *Agent, prev AgentStatus) {
+	switch {
+	case prev == StatusError && a.Status == StatusRunning:
+		a.Retries++
+	case a.Status == StatusIdle && a.Phase == PhaseDone:
+		a.Retries = 0
+	}
+}
+
+// lastError returns the agent's most recent failure.
+func (a Agent) lastError() (AgentError, bool) {
+	if len(a.Errors) == 0 {
+		return AgentError{}, false
+	}
+	return a.Errors[len(a.Errors)-1], true
+}
+
+// summary is the failure on one line: "Bash: 3 tests failed (exit 1)".
+func (e AgentError) summary() string {
+	s := e.Message
+	if e.Tool != "" {
+		s = e.Tool + ": " + s
+	}
+	if e.ExitCode != 0 {
+		s += fmt.Sprintf(" (exit %d)", e.ExitCode)
+	}
+	return s
+}
+
+// tailText returns the last n non-empty lines of s.
+func tailText(s string, n int) []string {
+	var lines []string
+	for _, l := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		if l = strings.TrimRight(l, "\r \t"); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines[max(len(lines)-n, 0):]
+}
@@ -0,0 +1,239 @@
+package main
+
+// Event log history — every agent's events kept on disk, without a cap.
+//
+// Sources keep only the last event_log_cap entries per agent. With
+// --event-log-dir (or event_log_dir under [source] in the config) the
+// dashboard appends each new entry to DIR/<agent id>.log as it arrives, and
+// the log pager reads the whole file. Entries already at the end of a file
+// from an earlier run are not written twice.
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// eventTailBytes is how much of an existing file is read back to find where
+// the previous run left off.
+const eventTailBytes = 1 << 20
+
+// eventStore appends agent event logs to per-agent files. It is owned by the
+// model; writes happen on its own goroutine so Update never waits on disk.
+// The queue is unbounded, so a slow disk delays writes rather than Update.
+type eventStore struct {
+	dir  string
+	wake chan struct{} // signalled when the queue fills
+	done chan struct{}
+
+	mu       sync.Mutex
+	queue    []eventAppend
+	closed   bool
+	err      error // first write error; later writes are skipped
+	reported bool
+}
+
+// eventAppend is one agent's log before and after an update.
+type eventAppend struct {
+	id         string
+	prev, next []string
+}
+
+func newEventStore(dir string) (*eventStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("event log: %w", err)
+	}
+	s := &eventStore{dir: dir, wake: make(chan struct{}, 1), done: make(chan struct{})}
+	go s.run()
+	return s, nil
+}
+
+// path is the file holding an agent's history.
+func (s *eventStore) path(id string) string {
+	return filepath.Join(s.dir, strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(id)+".log")
+}
+
+// observe queues whatever entries next added to prev's log. It never
+// blocks on the writer.
+func (s *eventStore) observe(prev, next Agent) {
+	if s == nil || slices.Equal(prev.EventLog, next.EventLog) {
+		return
+	}
+	s.mu.Lock()
+	s.queue = append(s.queue, eventAppend{next.ID, prev.EventLog, next.EventLog})
+	s.mu.Unlock()
+	s.signal()
+}
+
+func (s *eventStore) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// take empties the queue.
+func (s *eventStore) take() (batch []eventAppend, closed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batch, s.queue = s.queue, nil
+	return batch, s.closed
+}
+
+// Close writes out everything queued.
+func (s *eventStore) Close() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.signal()
+	<-s.done
+}
+
+// failed reports whether a write has failed.
+func (s *eventStore) failed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err != nil
+}
+
+// takeErr returns the write error the first time it is asked, for the
+// status bar.
+func (s *eventStore) takeErr() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.reported {
+		return nil
+	}
+	s.reported = s.err != nil
+	return s.err
+}
+
+func (s *eventStore) run() {
+	defer close(s.done)
+	seen := map[string]bool{}
+	for range s.wake {
+		batch, closed := s.take()
+		for _, ev := range batch {
+			s.write(ev, seen)
+		}
+		if closed {
+			return
+		}
+	}
+}
+
+// write appends one agent's new entries to its file.
+func (s *eventStore) write(ev eventAppend, seen map[string]bool) {
+	if s.failed() {
+		return
+	}
+	path := s.path(ev.id)
+	prev := ev.prev
+	if !seen[ev.id] {
+		// First sight this run: resume after what the file already has
+		seen[ev.id] = true
+		prev, _ = tailLines(path, len(ev.next))
+	}
+	fresh := newEntries(prev, ev.next)
+	if len(fresh) == 0 {
+		return
+	}
+	if err := appendLines(path, fresh); err != nil {
+		s.mu.Lock()
+		s.err = fmt.Errorf("event log: %w", err)
+		s.mu.Unlock()
+	}
+}
+
+// newEntries returns the entries of next that come after prev. Both are
+// windows onto the same growing log, so the longest suffix of prev that
+// starts next marks where the new entries begin.
+func newEntries(prev, next []string) []string {
+	for o := min(len(prev), len(next)); o > 0; o-- {
+		if slices.Equal(prev[len(prev)-o:], next[:o]) {
+			return next[o:]
+		}
+	}
+	return next
+}
+
+func appendLines(path string, lines []string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, l := range lines {
+		w.WriteString(strings.ReplaceAll(l, "\n", " ") + "\n")
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// tailLines returns up to n complete lines from the end of a file.
+func tailLines(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	start := max(info.Size()-eventTailBytes, 0)
+	data, err := io.ReadAll(io.NewSectionReader(f, start, info.Size()-start))
+	if err != nil {
+		return nil, err
+	}
+	if start > 0 { // drop the partial first line
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil, nil
+	}
+	return lines[max(len(lines)-n, 0):], nil
+}
+
+// readLines reads the complete lines of a file from offset on and returns
+// them with the offset just past the last one.
+func readLines(path string, offset int64) ([]string, int64, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, offset, nil // nothing written yet
+	}
+	if err != nil {
+		return nil, offset, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, offset, err
+	}
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil, offset, nil
+	}
+	return strings.Split(string(data[:end]), "\n"), offset + int64(end) + 1, nil
+}
@@ -0,0 +1,259 @@
+package main
+
+// Headless output — --json (one snapshot) and --watch (NDJSON stream).
+//
+// The JSON schema is versioned and decoupled from the Go structs so field
+// renames in Agent never break scripts. Bump snapshotSchemaVersion on any
+// incompatible change; additive fields keep the version.
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	snapshotSchema        = "pai-tui.snapshot"
+	snapshotSchemaVersion = 1
+)
+
+// jsonSnapshot is one complete fleet record.
+type jsonSnapshot struct {
+	Schema  string      `json:"schema"`
+	Version int         `json:"version"`
+	Time    time.Time   `json:"time"`
+	Summary jsonSummary `json:"summary"`
+	Agents  []jsonAgent `json:"agents"`
+}
+
+type jsonSummary struct {
+	Agents       int            `json:"agents"`
+	Status       map[string]int `json:"status"` // every status name, including zeros
+	TokensPerSec float64        `json:"tokens_per_sec"`
+}
+
+type jsonAgent struct {
+	ID            string        `json:"id"`
+	Name          string        `json:"name"`
+	Model         string        `json:"model"`
+	Status        string        `json:"status"` // running | idle | paused | error | stopped
+	Phase         string        `json:"phase"`  // observe … learn | done
+	Progress      int           `json:"progress"`
+	Task          string        `json:"task"`
+	StartedAt     time.Time     `json:"started_at"`
+	UptimeSeconds int64         `json:"uptime_seconds"`
+	LastActivity  string        `json:"last_activity"`
+	LastActiveAt  time.Time     `json:"last_active_at"`
+	CurrentTool   string        `json:"current_tool"`
+	ToolsUsed     int           `json:"tools_used"`
+	Tokens        jsonTokens    `json:"tokens"`
+	ISC           jsonISC       `json:"isc"`
+	PID           int           `json:"pid,omitempty"`
+	ExitCode      *int          `json:"exit_code,omitempty"`
+	WorkDir       string        `json:"work_dir,omitempty"`
+	ParentID      string        `json:"parent_id,omitempty"`
+	Retries       int           `json:"retries,omitempty"`
+	Errors        []jsonError   `json:"errors,omitempty"`   // oldest first
+	Question      *jsonQuestion `json:"question,omitempty"` // set while awaiting input
+	Phases        []jsonPhase   `json:"phases,omitempty"`   // oldest first
+}
+
+type jsonPhase struct {
+	Phase   string     `json:"phase"`
+	Start   time.Time  `json:"start"`
+	End     *time.Time `json:"end,omitempty"` // unset while the agent is in the phase
+	Tokens  int        `json:"tokens"`
+	Partial bool       `json:"partial,omitempty"` // began before the source saw the agent
+}
+
+type jsonQuestion struct {
+	Text    string    `json:"text"`
+	Options []string  `json:"options,omitempty"`
+	AskedAt time.Time `json:"asked_at"`
+}
+
+type jsonError struct {
+	Message  string    `json:"message"`
+	Tool     string    `json:"tool,omitempty"`
+	ExitCode int       `json:"exit_code,omitempty"`
+	Stderr   []string  `json:"stderr,omitempty"`
+	Time     time.Time `json:"time"`
+	Retries  int       `json:"retries"`
+}
+
+type jsonTokens struct {
+	PerSec float64 `json:"per_sec"`
+	In     int     `json:"in"`
+	Out    int     `json:"out"`
+	Total  int     `json:"total"`
+}
+
+type jsonISC struct {
+	Passed   int                `json:"passed"`
+	Total    int                `json:"total"`
+	Criteria []jsonISCCriterion `json:"criteria"`
+}
+
+type jsonISCCriterion struct {
+	Text       string     `json:"text"`
+	Passed     bool       `json:"passed"`
+	Evidence   string     `json:"evidence,omitempty"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+}
+
+// newSnapshot converts agents into the exported schema.
+func newSnapshot(agents []Agent, now time.Time) jsonSnapshot {
+	sum := summarize(agents)
+	snap := jsonSnapshot{
+		Schema:  snapshotSchema,
+		Version: snapshotSchemaVersion,
+		Time:    now,
+		Summary: jsonSummary{
+			Agents:       sum.Total,
+			Status:       map[string]int{},
+			TokensPerSec: sum.TokensPerSec,
+		},
+		Agents: make([]jsonAgent, 0, len(agents)),
+	}
+	for st := StatusRunning; st <= StatusStopped; st++ {
+		snap.Summary.Status[strings.ToLower(st.String())] = sum.Counts[st]
+	}
+	for _, a := range agents {
+		snap.Agents = append(snap.Agents, toJSONAgent(a, now))
+	}
+	return snap
+}
+
+func toJSONAgent(a Agent, now time.Time) jsonAgent {
+	ja := jsonAgent{
+		ID:           a.ID,
+		Name:         a.Name,
+		Model:        a.Model,
+		Status:       strings.ToLower(a.Status.String()),
+		Phase:        strings.ToLower(a.Phase.String()),
+		Progress:     a.Progress,
+		Task:         a.TaskDesc,
+		StartedAt:    a.StartedAt,
+		LastActivity: a.LastActivity,
+		LastActiveAt: a.LastActTime,
+		CurrentTool:  a.CurrentTool,
+		ToolsUsed:    a.ToolsUsed,
+		Tokens: jsonTokens{
+			PerSec: a.TokensPerSec,
+			In:     a.TotalTokensIn,
+			Out:    a.TotalTokensOut,
+			Total:  a.TotalTokensIn + a.TotalTokensOut,
+		},
+		ISC:      jsonISC{Total: len(a.ISCItems), Criteria: []jsonISCCriterion{}},
+		PID:      a.PID,
+		WorkDir:  a.WorkDir,
+		ParentID: a.ParentID,
+		Retries:  a.Retries,
+	}
+	if a.Status != StatusStopped {
+		ja.UptimeSeconds = int64(now.Sub(a.StartedAt).Seconds())
+	}
+	if a.PID != 0 && a.Status != StatusRunning && a.Status != StatusPaused {
+		code := a.ExitCode
+		ja.ExitCode = &code
+	}
+	for _, e := range a.Errors {
+		ja.Errors = append(ja.Errors, jsonError{
+			Message: e.Message, Tool: e.Tool, ExitCode: e.ExitCode,
+			Stderr: e.Stderr, Time: e.Time, Retries: e.Retries,
+		})
+	}
+	if a.waiting() {
+		ja.Question = &jsonQuestion{Text: a.Question.Text, Options: a.Question.Options, AskedAt: a.Question.Asked}
+	}
+	ja.Phases = toJSONPhases(a.Phases)
+	for _, c := range a.ISCItems {
+		jc := jsonISCCriterion{Text: c.Text, Passed: c.Passed, Evidence: c.Evidence}
+		if !c.VerifiedAt.IsZero() {
+			t := c.VerifiedAt
+			jc.VerifiedAt = &t
+		}
+		if c.Passed {
+			ja.ISC.Passed++
+		}
+		ja.ISC.Criteria = append(ja.ISC.Criteria, jc)
+	}
+	return ja
+}
+
+func toJSONPhases(spans []PhaseSpan) []jsonPhase {
+	var out []jsonPhase
+	for _, s := range spans {
+		jp := jsonPhase{Phase: strings.ToLower(s.Phase.String()), Start: s.Start, Tokens: s.Tokens, Partial: s.Partial}
+		if !s.End.IsZero() {
+			t := s.End
+			jp.End = &t
+		}
+		out = append(out, jp)
+	}
+	return out
+}
+
+// writeJSON prints a single indented snapshot of the source.
+func writeJSON(w io.Writer, src AgentSource) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(newSnapshot(src.Snapshot(), time.Now()))
+}
+
+// watchJSON prints one compact snapshot per line: the initial state, then
+// one record per update, until the source ends or the process is interrupted.
+func watchJSON(w io.Writer, src AgentSource) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	enc := json.NewEncoder(w) // Encode appends the newline NDJSON needs
+	// Agents by ID, plus the IDs in the order they were first seen
+	agents := map[string]Agent{}
+	var order []string
+	for _, a := range src.Snapshot() {
+		agents[a.ID] = a
+		order = append(order, a.ID)
+	}
+	fleet := func() []Agent {
+		out := make([]Agent, 0, len(order))
+		for _, id := range order {
+			out = append(out, agents[id])
+		}
+		return out
+	}
+	if err := enc.Encode(newSnapshot(fleet(), time.Now())); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case u, ok := <-src.Updates():
+			if !ok {
+				return nil
+			}
+			for _, a := range u.Agents {
+				if _, ok := agents[a.ID]; !ok {
+					order = append(order, a.ID)
+				}
+				agents[a.ID] = a
+			}
+			for _, id := range u.Removed {
+				if _, ok := agents[id]; ok {
+					delete(agents, id)
+					order = slices.DeleteFunc(order, func(o string) bool { return o == id })
+				}
+			}
+			if err := enc.Encode(newSnapshot(fleet(), time.Now())); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+// Agent filter — the query typed after '/'.
+//
+// A query is whitespace-separated terms, all of which must match:
+//
+//	engineer auth        fuzzy: each word is a subsequence of ID, name, task or activity
+//	status:error         status, phase: prefix of the name (phase:ver)
+//	model:grok name:qa   model, name, id, tool, parent: substring
+//	tok<50 progress>=80  tok, progress, cost, tools: numeric comparison (tok: running agents only)
+//	!status:idle         any term may be negated with a leading '!'
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// filterTerm is one parsed query term.
+type filterTerm struct {
+	negate bool
+	match  func(a Agent, p Pricing) bool
+}
+
+// agentFilter is a parsed query. The zero value matches everything.
+type agentFilter struct {
+	text  string
+	terms []filterTerm
+}
+
+func (f agentFilter) active() bool { return len(f.terms) > 0 }
+
+func (f agentFilter) matches(a Agent, p Pricing) bool {
+	for _, t := range f.terms {
+		if t.match(a, p) == t.negate {
+			return false
+		}
+	}
+	return true
+}
+
+var numericFields = map[string]func(Agent, Pricing) float64{
+	"tok":      func(a Agent, _ Pricing) float64 { return shownTokRate(a) },
+	"progress": func(a Agent, _ Pricing) float64 { return float64(a.Progress) },
+	"cost":     func(a Agent, p Pricing) float64 { return p.Cost(a) },
+	"tools":    func(a Agent, _ Pricing) float64 { return float64(a.ToolsUsed) },
+}
+
+var textFields = map[string]func(Agent) string{
+	"model":  func(a Agent) string { return a.Model },
+	"name":   func(a Agent) string { return a.Name },
+	"id":     func(a Agent) string { return a.ID },
+	"tool":   func(a Agent) string { return a.CurrentTool },
+	"parent": func(a Agent) string { return a.ParentID },
+}
+
+// parseFilter compiles a query, reporting the first term it cannot use.
+func parseFilter(text string) (agentFilter, error) {
+	f := agentFilter{text: strings.TrimSpace(text)}
+	for _, word := range strings.Fields(text) {
+		t, err := parseTerm(strings.ToLower(word))
+		if err != nil {
+			return agentFilter{}, err
+		}
+		f.terms = append(f.terms, t)
+	}
+	return f, nil
+}
+
+func parseTerm(word string) (filterTerm, error) {
+	t := filterTerm{}
+	if strings.HasPrefix(word, "!") && len(word) > 1 {
+		t.negate = true
+		word = word[1:]
+	}
+
+	// Numeric comparison: field op number
+	if i := strings.IndexAny(word, "<>="); i > 0 {
+		field, rest := word[:i], word[i:]
+		value, ok := numericFields[field]
+		if !ok {
+			return t, fmt.Errorf("unknown field %q (numeric fields: tok, progress, cost, tools)", field)
+		}
+		op := rest[:1]
+		if len(rest) > 1 && rest[1] == '=' {
+			op = rest[:2]
+		}
+		n, err := strconv.ParseFloat(strings.TrimPrefix(rest[len(op):], "$"), 64)
+		if err != nil {
+			return t, fmt.Errorf("%s: %q is not a number", field, rest[len(op):])
+		}
+		cmp, ok := map[string]func(a, b float64) bool{
+			"<": func(a, b float64) bool { return a < b }, "<=": func(a, b float64) bool { return a <= b },
+			">": func(a, b float64) bool { return a > b }, ">=": func(a, b float64) bool { return a >= b },
+			"=": func(a, b float64) bool { return a == b },
+		}[op]
+		if !ok {
+			return t, fmt.Errorf("bad comparison %q", op)
+		}
+		t.match = func(a Agent, p Pricing) bool { return cmp(value(a, p), n) }
+		if field == "tok" {
+			// Only running agents report a throughput. The rest are not
+			// slow, so tok<50 must not match them
+			t.match = func(a Agent, p Pricing) bool { return a.Status == StatusRunning && cmp(value(a, p), n) }
+		}
+		return t, nil
+	}
+
+	// Structured term: field:value
+	if field, value, ok := strings.Cut(word, ":"); ok {
+		switch field {
+		case "status":
+			st, err := matchPrefix(value, []string{"running", "idle", "paused", "error", "stopped"})
+			if err != nil {
+				return t, fmt.Errorf("status: %w", err)
+			}
+			t.match = func(a Agent, _ Pricing) bool { return int(a.Status) == st }
+			return t, nil
+		case "phase":
+			ph, err := matchPrefix(value, lowerAll(phaseNames[:]))
+			if err != nil {
+				return t, fmt.Errorf("phase: %w", err)
+			}
+			t.match = func(a Agent, _ Pricing) bool { return int(a.Phase) == ph }
+			return t, nil
+		}
+		get, ok := textFields[field]
+		if !ok {
+			return t, fmt.Errorf("unknown field %q", field)
+		}
+		t.match = func(a Agent, _ Pricing) bool { return strings.Contains(strings.ToLower(get(a)), value) }
+		return t, nil
+	}
+
+	// Fuzzy text
+	t.match = func(a Agent, _ Pricing) bool {
+		for _, s := range []string{a.ID, a.Name, a.TaskDesc, a.LastActivity} {
+			if fuzzyMatch(word, strings.ToLower(s)) {
+				return true
+			}
+		}
+		return false
+	}
+	return t, nil
+}
+
+// matchPrefix finds the single option that value is a prefix of.
+func matchPrefix(value string, options []string) (int, error) {
+	found := -1
+	for i, o := range options {
+		if strings.HasPrefix(o, value) {
+			if found >= 0 {
+				return 0, fmt.Errorf("%q is ambiguous", value)
+			}
+			found = i
+		}
+	}
+	if found < 0 {
+		return 0, fmt.Errorf("%q matches none of %s", value, strings.Join(options, ", "))
+	}
+	return found, nil
+}
+
+// fuzzyMatch reports whether the runes of pattern appear in s in order.
+func fuzzyMatch(pattern, s string) bool {
+	p := []rune(pattern)
+	i := 0
+	for _, r := range s {
+		if i < len(p) && r == p[i] {
+			i++
+		}
+	}
+	return i == len(p)
+}
+
+func lowerAll(in []string) []string {
+	out := make([]string, len(in))
+	for i, s := range in {
+		out[i] = strings.ToLower(s)
+	}
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Prompt
+// ---------------------------------------------------------------------------
+
+func newFilterInput(text string) textinput.Model {
+	in := textinput.New()
+	in.Prompt = "/ "
+	in.Placeholder = "fuzzy text, status:error, model:grok, phase:verify, tok<50"
+	in.SetValue(text)
+	in.Cursor.SetMode(cursor.CursorStatic)
+	in.Focus()
+	return in
+}
+
+// updateFilter routes keys to the open filter prompt. The table follows every
+// keystroke; a query that does not parse keeps the last good filter and shows
+// why in the prompt.
+func (m model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.filtering = false
+		return m, nil
+	case "esc":
+		m.filtering = false
+		m.setFilter(agentFilter{})
+		return m, nil
+	case "up", "down":
+		m.moveCursor(map[string]int{"up": -1, "down": 1}[msg.String()])
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	f, err := parseFilter(m.filterInput.Value())
+	m.filterErr = ""
+	if err != nil {
+		m.filterErr = err.Error()
+		return m, cmd
+	}
+	m.setFilter(f)
+	return m, cmd
+}
+
+// setFilter swaps the active filter, keeping the selected agent if it still
+// matches.
+func (m *model) setFilter(f agentFilter) {
+	cur, _ := m.selected()
+	m.filter = f
+	m.filterErr = ""
+	keys.ClearFilter.SetEnabled(f.active())
+	m.keepSelection(cur.ID)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilterMatches(t *testing.T) {
+	eng := Agent{ID: "pai-1a2b", Name: "Engineer", Model: "claude-opus-4-6", Status: StatusRunning, Phase: PhaseVerify,
+		Progress: 80, TokensPerSec: 42, ToolsUsed: 25, CurrentTool: "Bash", TaskDesc: "Implement auth middleware",
+		TotalTokensOut: 100000}
+	intern := Agent{ID: "pai-3c4d", Name: "Intern", Model: "grok-3", Status: StatusIdle, Phase: PhaseDone,
+		Progress: 100, TokensPerSec: 12, ParentID: "pai-1a2b", LastActivity: "Read README.md"}
+	pricing := defaultPricing()
+
+	tests := []struct {
+		query string
+		want  string // IDs that match, space-separated
+	}{
+		{"", "pai-1a2b pai-3c4d"},
+		{"engineer", "pai-1a2b"},
+		{"ENGINEER", "pai-1a2b"},
+		{"eng auth", "pai-1a2b"}, // every term must match
+		{"imw", "pai-1a2b"},      // fuzzy: a subsequence of the task
+		{"readme", "pai-3c4d"},   // fuzzy: last activity
+		{"1a2b", "pai-1a2b"},     // fuzzy: ID
+		{"engineer intern", ""},  // terms are ANDed
+		{"status:running", "pai-1a2b"},
+		{"status:i", "pai-3c4d"}, // unique prefix
+		{"phase:ver", "pai-1a2b"},
+		{"phase:done", "pai-3c4d"},
+		{"model:grok", "pai-3c4d"},
+		{"model:CLAUDE", "pai-1a2b"},
+		{"name:ern", "pai-3c4d"},
+		{"id:pai-", "pai-1a2b pai-3c4d"},
+		{"tool:bash", "pai-1a2b"},
+		{"parent:1a2b", "pai-3c4d"},
+		{"progress>=80", "pai-1a2b pai-3c4d"},
+		{"progress>80", "pai-3c4d"},
+		{"progress=100", "pai-3c4d"},
+		{"progress<80", ""},
+		{"progress<=80", "pai-1a2b"},
+		{"tools>20", "pai-1a2b"},
+		{"cost>2", "pai-1a2b"}, // 100k output tokens at $25/M
+		{"cost>$2", "pai-1a2b"},
+		{"cost<0.01", "pai-3c4d"},
+		{"tok<50", "pai-1a2b"}, // the idle intern has no throughput to compare
+		{"tok>10", "pai-1a2b"},
+		{"!tok<50", "pai-3c4d"},
+		{"!status:idle", "pai-1a2b"},
+		{"!engineer", "pai-3c4d"},
+		{"!", ""}, // a lone "!" is searched for as text
+	}
+	for _, tt := range tests {
+		f, err := parseFilter(tt.query)
+		if err != nil {
+			t.Errorf("%q: %v", tt.query, err)
+			continue
+		}
+		var got []string
+		for _, a := range []Agent{eng, intern} {
+			if f.matches(a, pricing) {
+				got = append(got, a.ID)
+			}
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%q matches %q, want %q", tt.query, strings.Join(got, " "), tt.want)
+		}
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"status:bogus", `status: "bogus" matches none of`},
+		{"status:", `status: "" is ambiguous`},
+		{"phase:x", `phase: "x" matches none of`},
+		{"colour:red", `unknown field "colour"`},
+		{"speed>5", `unknown field "speed" (numeric fields`},
+		{"tok<fast", `tok: "fast" is not a number`},
+		{"tok<", `tok: "" is not a number`},
+		{"progress=>5", `progress: ">5" is not a number`},
+		{"engineer status:nope", `status: "nope"`}, // the first bad term is reported
+	}
+	for _, tt := range tests {
+		f, err := parseFilter(tt.query)
+		if err == nil {
+			t.Errorf("%q: no error", tt.query)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: error %q, want one containing %q", tt.query, err, tt.want)
+		}
+		if f.active() {
+			t.Errorf("%q: a query that does not parse must not filter", tt.query)
+		}
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"", "anything", true},
+		{"eng", "engineer", true},
+		{"egr", "engineer", true},
+		{"rge", "engineer", false},
+		{"engineers", "engineer", false},
+		{"ü", "über", true},
+	}
+	for _, tt := range tests {
+		if got := fuzzyMatch(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+// Per-agent history — a short ring of samples taken on every UI tick, drawn
+// as a sparkline in the table and a braille chart in the detail pane.
+//
+// History belongs to the dashboard, not the source: it is sampled from
+// whatever the model currently shows, so it works the same for simulated,
+// live and replayed agents and is dropped when an agent goes away.
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const historyLen = 60 // samples kept per series (2 minutes at the default 2s tick)
+
+// ring is a fixed-size series of samples, oldest overwritten first.
+type ring struct {
+	buf  [historyLen]float64
+	head int // next write position
+	n    int
+}
+
+func (r *ring) push(v float64) {
+	r.buf[r.head] = v
+	r.head = (r.head + 1) % historyLen
+	if r.n < historyLen {
+		r.n++
+	}
+}
+
+// values returns the samples oldest first.
+func (r *ring) values() []float64 {
+	out := make([]float64, r.n)
+	start := (r.head - r.n + historyLen) % historyLen
+	for i := range out {
+		out[i] = r.buf[(start+i)%historyLen]
+	}
+	return out
+}
+
+// agentHistory is everything recorded for one agent.
+type agentHistory struct {
+	tokRate   ring
+	progress  ring
+	toolCalls ring // tool calls made since the previous sample
+	lastTools int
+}
+
+// sampleHistory records one sample per agent and for the whole fleet, and
+// forgets agents that are gone.
+func (m *model) sampleHistory() {
+	present := make(map[string]bool, len(m.agents))
+	total := 0.0
+	for _, a := range m.agents {
+		present[a.ID] = true
+		total += shownTokRate(a)
+		h, ok := m.history[a.ID]
+		if !ok {
+			h = &agentHistory{lastTools: a.ToolsUsed}
+			m.history[a.ID] = h
+		}
+		h.tokRate.push(shownTokRate(a))
+		h.progress.push(float64(a.Progress))
+		h.toolCalls.push(float64(max(a.ToolsUsed-h.lastTools, 0)))
+		h.lastTools = a.ToolsUsed
+	}
+	for id := range m.history {
+		if !present[id] {
+			delete(m.history, id)
+		}
+	}
+	m.fleet.push(total, time.Now())
+}
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws the last width values as block characters scaled to top,
+// or to the largest of them when top is 0. It is right-aligned so the newest
+// sample is always at the edge.
+func sparkline(vals []float64, width int, top float64) string {
+	if len(vals) > width {
+		vals = vals[len(vals)-width:]
+	}
+	if top == 0 {
+		top = peak(vals)
+	}
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", width-len(vals)))
+	for _, v := range vals {
+		i := 0
+		if top > 0 {
+			i = int(math.Round(math.Min(v/top, 1) * float64(len(sparkBlocks)-1)))
+		}
+		b.WriteRune(sparkBlocks[i])
+	}
+	return b.String()
+}
+
+func peak(vals []float64) float64 {
+	top := 0.0
+	for _, v := range vals {
+		top = math.Max(top, v)
+	}
+	return top
+}
+
+// pairs halves a series by keeping the larger of each pair of samples,
+// counting from the newest, so a sparkline lines up with a braille chart.
+func pairs(vals []float64) []float64 {
+	out := make([]float64, (len(vals)+1)/2)
+	for i := range out {
+		j := len(vals) - 1 - 2*i
+		v := vals[j]
+		if j > 0 {
+			v = math.Max(v, vals[j-1])
+		}
+		out[len(out)-1-i] = v
+	}
+	return out
+}
+
+// brailleChart draws vals as a filled area chart, two samples per column and
+// four dot rows per line, scaled from zero to top. Lines are returned top
+// first.
+func brailleChart(vals []float64, cols, lines int, top float64) []string {
+	if len(vals) > cols*2 {
+		vals = vals[len(vals)-cols*2:]
+	}
+	// Dot bits for the left and right halves of a cell, bottom row first.
+	left := [4]rune{0x40, 0x04, 0x02, 0x01}
+	right := [4]rune{0x80, 0x20, 0x10, 0x08}
+
+	dotsH := lines * 4
+	cells := make([][]rune, lines)
+	for i := range cells {
+		cells[i] = []rune(strings.Repeat("⠀", cols))
+	}
+	offset := cols*2 - len(vals) // right-align like the sparkline
+	for i, v := range vals {
+		x := offset + i
+		h := 0
+		if top > 0 {
+			h = int(math.Round(math.Min(v/top, 1) * float64(dotsH)))
+		}
+		if h == 0 && v > 0 {
+			h = 1 // keep small non-zero values visible
+		}
+		bits := left
+		if x%2 == 1 {
+			bits = right
+		}
+		for d := 0; d < h; d++ {
+			line := lines - 1 - d/4
+			cells[line][x/2] |= bits[d%4]
+		}
+	}
+	out := make([]string, lines)
+	for i, c := range cells {
+		out[i] = string(c)
+	}
+	return out
+}
+
+// renderHistory is the detail pane's history section: a braille chart of
+// throughput with sparklines for progress and tool calls underneath.
+func renderHistory(h *agentHistory, w int, th Theme) string {
+	title := lipgloss.NewStyle().Bold(true).Foreground(th.Title)
+	label := lipgloss.NewStyle().Bold(true).Foreground(th.Fg)
+	dim := lipgloss.NewStyle().Foreground(th.Dim)
+	chart := lipgloss.NewStyle().Foreground(th.Bar)
+
+	var b strings.Builder
+	b.WriteString(title.Render("History") + dim.Render(fmt.Sprintf("  last %d samples", h.tokRate.n)) + "\n")
+
+	toks := h.tokRate.values()
+	top := peak(toks)
+	cols := min(historyLen/2, max(w-24, 10))
+	row := func(name, axis, line string) {
+		b.WriteString(fmt.Sprintf("  %s %s %s\n",
+			label.Render(fmt.Sprintf("%-9s", name)), dim.Render(fmt.Sprintf("%5s", axis)), chart.Render(line)))
+	}
+	for i, line := range brailleChart(toks, cols, 3, top) {
+		switch i {
+		case 0:
+			row("Tok/s:", fmt.Sprintf("%.0f", top), line)
+		case 2:
+			row("", "0", line)
+		default:
+			row("", "", line)
+		}
+	}
+	row("Progress:", "", sparkline(pairs(h.progress.values()), cols, 100))
+	row("Tools:", "", sparkline(pairs(h.toolCalls.values()), cols, 0))
+	return b.String()
+}
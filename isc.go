@@ -0,0 +1,136 @@
+package main
+
+// ISC.json — Ideal State Criteria written by an agent into its WORK directory.
+//
+// The file is produced by PAI tooling and has drifted between versions, so the
+// loader accepts either {"criteria": [...]} or a bare array, and a handful of
+// spellings for each field:
+//
+//	{"criteria": [
+//	  {"text": "Tests pass", "passed": true, "evidence": "go test ok",
+//	   "verified_at": "2026-01-02T15:04:05Z"},
+//	  {"criterion": "Lint green", "status": "fail"}
+//	]}
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const iscFileName = "ISC.json"
+
+// defaultWorkRoot is where PAI keeps per-session WORK directories.
+func defaultWorkRoot() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".claude", "MEMORY", "WORK")
+	}
+	return filepath.Join(home, ".claude", "MEMORY", "WORK")
+}
+
+type iscRecord struct {
+	Text         string `json:"text"`
+	Criterion    string `json:"criterion"`
+	Passed       *bool  `json:"passed"`
+	Status       string `json:"status"`
+	Evidence     string `json:"evidence"`
+	VerifiedAt   string `json:"verified_at"`
+	LastVerified string `json:"last_verified"`
+}
+
+// loadISC parses an ISC.json file into criteria.
+func loadISC(path string) ([]ISCCriterion, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var recs []iscRecord
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(data, &recs)
+	} else {
+		var doc struct {
+			Criteria []iscRecord `json:"criteria"`
+		}
+		err = json.Unmarshal(data, &doc)
+		recs = doc.Criteria
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	items := make([]ISCCriterion, 0, len(recs))
+	for _, r := range recs {
+		c := ISCCriterion{
+			Text:     firstNonEmpty(r.Text, r.Criterion),
+			Evidence: r.Evidence,
+		}
+		if r.Passed != nil {
+			c.Passed = *r.Passed
+		} else {
+			switch strings.ToLower(r.Status) {
+			case "pass", "passed", "ok", "done", "verified":
+				c.Passed = true
+			}
+		}
+		if ts := firstNonEmpty(r.VerifiedAt, r.LastVerified); ts != "" {
+			if t, err := time.Parse(time.RFC3339, ts); err == nil {
+				c.VerifiedAt = t
+			}
+		}
+		items = append(items, c)
+	}
+	return items, nil
+}
+
+// findWorkDir locates a session's WORK directory under root: either a
+// directory named after the session or one whose name contains it.
+func findWorkDir(root, sessionID string) string {
+	if sessionID == "" {
+		return ""
+	}
+	if dir := filepath.Join(root, sessionID); isDir(dir) {
+		return dir
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return ""
+	}
+	for _, e := range entries {
+		if e.IsDir() && strings.Contains(e.Name(), sessionID) {
+			return filepath.Join(root, e.Name())
+		}
+	}
+	return ""
+}
+
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// iscWatch remembers the last-seen state of one ISC.json so it is only
+// re-read after it changes on disk.
+type iscWatch struct {
+	modTime time.Time
+	size    int64
+}
+
+// changed stats path and reports whether it differs from the last call.
+// A file that disappears counts as a change once.
+func (w *iscWatch) changed(path string) bool {
+	fi, err := os.Stat(path)
+	if err != nil {
+		gone := !w.modTime.IsZero()
+		*w = iscWatch{}
+		return gone
+	}
+	if fi.ModTime().Equal(w.modTime) && fi.Size() == w.size {
+		return false
+	}
+	w.modTime, w.size = fi.ModTime(), fi.Size()
+	return true
+}
@@ -0,0 +1,212 @@
+package main
+
+// Key remapping and the full-screen help overlay.
+//
+// Every binding can be replaced from the [keys] table of the config file,
+// by action name, with one key or a list:
+//
+//	[keys]
+//	up    = ["k", "up", "ctrl+p"]
+//	down  = ["j", "down", "ctrl+n"]
+//	pause = "P"
+//
+// A binding given here replaces the default keys for that action. Two
+// actions may not share a key, and the digits are kept for count prefixes
+// (5j moves down five rows, 3G jumps to row 3).
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keyList is one [keys] entry: a single key or an array of keys.
+type keyList []string
+
+func (l *keyList) UnmarshalTOML(v any) error {
+	switch v := v.(type) {
+	case string:
+		*l = keyList{v}
+	case []any:
+		*l = make(keyList, len(v))
+		for i, k := range v {
+			s, ok := k.(string)
+			if !ok {
+				return fmt.Errorf("want a key name or a list of key names")
+			}
+			(*l)[i] = s
+		}
+	default:
+		return fmt.Errorf("want a key name or a list of key names")
+	}
+	return nil
+}
+
+// actions maps the config name of every remappable binding to it.
+func (k *keyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up": &k.Up, "down": &k.Down, "top": &k.Top, "bottom": &k.Bottom,
+		"half_page_down": &k.HalfDown, "half_page_up": &k.HalfUp,
+		"detail": &k.Enter, "refresh": &k.Refresh, "start_stop": &k.Toggle, "pause": &k.Pause,
+		"new": &k.New, "answer": &k.Answer, "queue": &k.Queue, "overview": &k.Overview, "sort": &k.Sort, "reverse": &k.Reverse,
+		"filter": &k.Filter, "clear_filter": &k.ClearFilter,
+		"tree": &k.Tree, "collapse": &k.Collapse, "expand": &k.Expand,
+		"alerts": &k.Alerts, "theme": &k.Theme, "event_log": &k.Log, "help": &k.Help, "quit": &k.Quit,
+		"play": &k.Play, "slower": &k.Slower, "faster": &k.Faster,
+		"seek_back": &k.SeekBack, "seek_forward": &k.SeekFwd,
+	}
+}
+
+// namedKeys are the non-character keys Bubble Tea reports by name.
+var namedKeys = map[string]bool{
+	"up": true, "down": true, "left": true, "right": true, "home": true, "end": true,
+	"pgup": true, "pgdown": true, "enter": true, "esc": true, "tab": true, "shift+tab": true,
+	"backspace": true, "delete": true, "insert": true, " ": true,
+	"f1": true, "f2": true, "f3": true, "f4": true, "f5": true, "f6": true,
+	"f7": true, "f8": true, "f9": true, "f10": true, "f11": true, "f12": true,
+}
+
+// normalizeKey turns a config key name into the string Bubble Tea reports,
+// or returns "" when it is not a key.
+func normalizeKey(s string) string {
+	if s == "space" {
+		return " "
+	}
+	base := strings.TrimPrefix(strings.TrimPrefix(s, "alt+"), "ctrl+")
+	if base != s && len(base) == 1 && base[0] >= 'a' && base[0] <= 'z' {
+		return s
+	}
+	if namedKeys[base] {
+		return s
+	}
+	if r, n := utf8.DecodeRuneInString(base); n == len(base) && n > 0 && unicode.IsPrint(r) && !strings.HasPrefix(s, "ctrl+") {
+		return s
+	}
+	return ""
+}
+
+// checkKeys validates a [keys] table against the defaults it changes.
+func checkKeys(over map[string]keyList) error {
+	def := defaultKeyMap()
+	actions := def.actions()
+	owner := map[string]string{}
+	for _, name := range sortedKeys(actions) {
+		ks := actions[name].Keys()
+		if l, ok := over[name]; ok {
+			if len(l) == 0 {
+				return fmt.Errorf("keys.%s: no keys given", name)
+			}
+			ks = make([]string, len(l))
+			for i, k := range l {
+				if ks[i] = normalizeKey(k); ks[i] == "" {
+					return fmt.Errorf("keys.%s: %q is not a key", name, k)
+				}
+				if len(k) == 1 && k[0] >= '0' && k[0] <= '9' {
+					return fmt.Errorf("keys.%s: %q is reserved for count prefixes", name, k)
+				}
+			}
+		}
+		for _, k := range ks {
+			if other, ok := owner[k]; ok {
+				if _, remapped := over[name]; !remapped {
+					name, other = other, name // blame the entry the user wrote
+				}
+				return fmt.Errorf("keys.%s: %q is already bound to %s", name, k, other)
+			}
+			owner[k] = name
+		}
+	}
+	for _, name := range sortedKeys(over) {
+		if _, ok := actions[name]; !ok {
+			return fmt.Errorf("keys.%s: unknown action", name)
+		}
+	}
+	return nil
+}
+
+// applyKeys rebuilds the keymap from the defaults and a checked [keys]
+// table, keeping each binding enabled or disabled as it was.
+func applyKeys(over map[string]keyList) {
+	def := defaultKeyMap()
+	base := def.actions()
+	for name, b := range keys.actions() {
+		nb := *base[name]
+		if l, ok := over[name]; ok {
+			ks := make([]string, len(l))
+			for i, k := range l {
+				ks[i] = normalizeKey(k)
+			}
+			nb = key.NewBinding(key.WithKeys(ks...), key.WithHelp(keyLabel(ks), nb.Help().Desc))
+		}
+		nb.SetEnabled(b.Enabled())
+		*b = nb
+	}
+}
+
+var keyGlyphs = map[string]string{"up": "↑", "down": "↓", "left": "←", "right": "→", "enter": "⏎", " ": "space"}
+
+// keyLabel is the help text for a list of keys, e.g. "↑/k".
+func keyLabel(ks []string) string {
+	out := make([]string, len(ks))
+	for i, k := range ks {
+		out[i] = k
+		if g, ok := keyGlyphs[k]; ok {
+			out[i] = g
+		}
+	}
+	return strings.Join(out, "/")
+}
+
+type helpGroup struct {
+	title    string
+	bindings []key.Binding
+}
+
+func helpGroups(k keyMap) []helpGroup {
+	return []helpGroup{
+		{"Navigation", []key.Binding{k.Up, k.Down, k.Top, k.Bottom, k.HalfDown, k.HalfUp, k.Enter}},
+		{"Agents", []key.Binding{k.Toggle, k.Pause, k.New, k.Answer, k.Refresh}},
+		{"View", []key.Binding{k.Sort, k.Reverse, k.Filter, k.ClearFilter, k.Tree, k.Collapse, k.Expand, k.Alerts, k.Queue, k.Log, k.Overview, k.Theme}},
+		{"Replay", []key.Binding{k.Play, k.Slower, k.Faster, k.SeekBack, k.SeekFwd}},
+		{"General", []key.Binding{k.Help, k.Quit}},
+	}
+}
+
+// renderHelp is the ? overlay: every enabled binding, one column per group,
+// drawn by the help model in full mode.
+func (m model) renderHelp(w, h int) string {
+	title := lipgloss.NewStyle().Bold(true).Foreground(m.theme.Title)
+	dim := lipgloss.NewStyle().Foreground(m.theme.Dim)
+
+	hm := m.help
+	hm.ShowAll = true
+	var cols []string
+	for _, g := range helpGroups(keys) {
+		enabled := false
+		for _, b := range g.bindings {
+			enabled = enabled || b.Enabled()
+		}
+		if !enabled {
+			continue
+		}
+		body := hm.FullHelpView([][]key.Binding{g.bindings})
+		cols = append(cols, lipgloss.NewStyle().MarginRight(4).Render(title.Render(g.title)+"\n\n"+body))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		title.Render("Keybindings"), "",
+		lipgloss.JoinHorizontal(lipgloss.Top, cols...), "",
+		dim.Render("A number before a movement key repeats it: 5j, 2 ctrl+d, 12G."),
+		dim.Render("Mouse: click a row to select, double-click for detail, wheel to scroll, click a header to sort."),
+		dim.Render(keys.Help.Help().Key+"/esc close"))
+	box := lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(m.theme.Accent).
+		Padding(1, 2).
+		Render(content)
+	return lipgloss.Place(w, h, lipgloss.Center, lipgloss.Center, box)
+}
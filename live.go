@@ -0,0 +1,664 @@
+package main
+
+// Live agent source — tails the PAI hook event stream.
+//
+// PAI's capture hooks append one JSON object per line to files under
+// ~/.claude/history/raw-outputs/ (usually one file per day, nested in a
+// YYYY-MM directory). liveSource polls that tree, follows appends, picks up
+// new files, restarts from the top of a file that was truncated or replaced,
+// and folds every tool-call/activity record into a per-session Agent. Each
+// session's ISC.json (see isc.go) is re-read whenever it changes on disk.
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	livePollInterval = 500 * time.Millisecond
+	liveBacklogBytes = 256 * 1024       // how much of a pre-existing file to replay at startup
+	workDirRetry     = 30 * time.Second // between searches for a session's WORK directory
+)
+
+// defaultHistoryDir is where PAI writes its raw hook output.
+func defaultHistoryDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".claude", "history", "raw-outputs")
+	}
+	return filepath.Join(home, ".claude", "history", "raw-outputs")
+}
+
+// liveEvent is one line of a raw-outputs JSONL file. Only the fields the
+// dashboard uses are decoded; everything else is ignored.
+type liveEvent struct {
+	SessionID string          `json:"session_id"`
+	SourceApp string          `json:"source_app"`
+	HookEvent string          `json:"hook_event_type"`
+	Timestamp json.RawMessage `json:"timestamp"`
+	Payload   livePayload     `json:"payload"`
+}
+
+type livePayload struct {
+	SessionID    string          `json:"session_id"`
+	ToolName     string          `json:"tool_name"`
+	ToolInput    map[string]any  `json:"tool_input"`
+	ToolResponse json.RawMessage `json:"tool_response"`
+	Prompt       string          `json:"prompt"`
+	Message      string          `json:"message"`
+	Model        string          `json:"model"`
+	AgentType    string          `json:"agent_type"`
+	Phase        string          `json:"phase"`
+	Usage        *liveUsage      `json:"usage"`
+	WorkDir      string          `json:"work_dir"`
+	ParentID     string          `json:"parent_session_id"` // set on Task-spawned sub-agent sessions
+}
+
+type liveUsage struct {
+	InputTokens     int `json:"input_tokens"`
+	OutputTokens    int `json:"output_tokens"`
+	CacheReadTokens int `json:"cache_read_input_tokens"`
+}
+
+// tailFile tracks how far into one JSONL file we have read.
+type tailFile struct {
+	path    string
+	info    os.FileInfo
+	offset  int64
+	partial []byte // trailing bytes of an unterminated line
+}
+
+// liveSource turns the raw-outputs stream into Agent updates.
+type liveSource struct {
+	dir      string
+	workRoot string
+	interval time.Duration
+
+	mu      sync.Mutex
+	files   map[string]*tailFile
+	agents  map[string]*Agent // keyed by session ID
+	ids     map[string]string // agent ID → session ID
+	parents map[string]string // session ID → parent session ID
+	order   []string          // session IDs in first-seen order
+	changed map[string]bool   // session IDs touched since the last flush
+	isc     map[string]*iscWatch
+	noWork  map[string]time.Time // session ID → last search that found no WORK directory
+
+	updates chan AgentUpdate
+	rescan  chan struct{}
+	done    chan struct{}
+}
+
+func newLiveSource(dir, workRoot string) *liveSource {
+	return &liveSource{
+		dir:      dir,
+		workRoot: workRoot,
+		interval: livePollInterval,
+		files:    map[string]*tailFile{},
+		agents:   map[string]*Agent{},
+		ids:      map[string]string{},
+		parents:  map[string]string{},
+		changed:  map[string]bool{},
+		isc:      map[string]*iscWatch{},
+		noWork:   map[string]time.Time{},
+		updates:  make(chan AgentUpdate, 16),
+		rescan:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start replays the recent backlog synchronously (so the first frame already
+// has agents) and then tails the directory in the background.
+func (s *liveSource) Start() {
+	s.scan(true)
+	s.flush()
+	go s.run()
+}
+
+// Stop ends the background tailer and closes the update channel.
+func (s *liveSource) Stop() {
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+}
+
+// Refresh asks the tailer to poll immediately instead of waiting a full interval.
+func (s *liveSource) Refresh() {
+	select {
+	case s.rescan <- struct{}{}:
+	default:
+	}
+}
+
+// Snapshot returns a copy of every known agent in first-seen order.
+func (s *liveSource) Snapshot() []Agent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Agent, 0, len(s.order))
+	for _, sid := range s.order {
+		out = append(out, copyAgent(*s.agents[sid]))
+	}
+	return out
+}
+
+// Updates delivers batches of changed agents. It is closed by Stop.
+func (s *liveSource) Updates() <-chan AgentUpdate { return s.updates }
+
+// Control is not available for observed sessions: the dashboard only reads
+// the hook stream and has no handle on the processes that write it.
+func (s *liveSource) Control(string, ControlAction) error { return errUnsupported }
+
+func (s *liveSource) run() {
+	t := time.NewTicker(s.interval)
+	defer t.Stop()
+	defer close(s.updates)
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-t.C:
+		case <-s.rescan:
+		}
+		s.scan(false)
+		if batch := s.flush(); len(batch) > 0 {
+			select {
+			case s.updates <- AgentUpdate{Agents: batch}:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
+
+// flush returns copies of every agent changed since the last flush.
+func (s *liveSource) flush() []Agent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.changed) == 0 {
+		return nil
+	}
+	batch := make([]Agent, 0, len(s.changed))
+	for _, sid := range s.order {
+		if s.changed[sid] {
+			batch = append(batch, copyAgent(*s.agents[sid]))
+		}
+	}
+	s.changed = map[string]bool{}
+	return batch
+}
+
+// scan walks the history directory and reads any new bytes from each file.
+func (s *liveSource) scan(initial bool) {
+	seen := map[string]bool{}
+	_ = filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // directory may not exist yet; keep polling
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".jsonl") {
+			return nil
+		}
+		seen[path] = true
+		s.readFile(path, initial)
+		return nil
+	})
+	for path := range s.files {
+		if !seen[path] {
+			delete(s.files, path) // rotated away
+		}
+	}
+	s.refreshISC()
+}
+
+// refreshISC locates each session's WORK directory and reloads its ISC.json
+// when the file has changed since the last scan. The disk work happens
+// outside s.mu, and a session whose directory was not found is searched for
+// again only after workDirRetry.
+func (s *liveSource) refreshISC() {
+	type iscJob struct {
+		sid, dir string
+		found    bool // dir came from the agent, not a search
+		watch    *iscWatch
+		items    []ISCCriterion
+		err      error
+		changed  bool
+	}
+	now := time.Now()
+	var jobs []iscJob
+	s.mu.Lock()
+	for _, sid := range s.order {
+		dir := s.agents[sid].WorkDir
+		if dir == "" && now.Sub(s.noWork[sid]) < workDirRetry {
+			continue
+		}
+		w, ok := s.isc[sid]
+		if !ok {
+			w = &iscWatch{}
+			s.isc[sid] = w
+		}
+		jobs = append(jobs, iscJob{sid: sid, dir: dir, found: dir != "", watch: w})
+	}
+	s.mu.Unlock()
+
+	for i := range jobs {
+		j := &jobs[i]
+		if j.dir == "" {
+			if j.dir = findWorkDir(s.workRoot, j.sid); j.dir == "" {
+				continue
+			}
+		}
+		path := filepath.Join(j.dir, iscFileName)
+		if !j.watch.changed(path) {
+			continue
+		}
+		j.changed = true
+		j.items, j.err = loadISC(path)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range jobs {
+		a, ok := s.agents[j.sid]
+		if !ok {
+			continue
+		}
+		if !j.found {
+			if j.dir == "" {
+				s.noWork[j.sid] = now
+				continue
+			}
+			delete(s.noWork, j.sid)
+			if a.WorkDir == "" {
+				a.WorkDir = j.dir
+			}
+		}
+		if !j.changed {
+			continue
+		}
+		if j.err != nil && !os.IsNotExist(j.err) {
+			a.EventLog = appendEvent(a.EventLog, now, "ISC.json: "+j.err.Error())
+			s.changed[j.sid] = true
+			continue
+		}
+		a.ISCItems = j.items
+		s.changed[j.sid] = true
+	}
+}
+
+func (s *liveSource) readFile(path string, initial bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	tf, ok := s.files[path]
+	switch {
+	case !ok:
+		tf = &tailFile{path: path, info: info}
+		if initial && info.Size() > liveBacklogBytes {
+			tf.offset = info.Size() - liveBacklogBytes
+		}
+		s.files[path] = tf
+	case !os.SameFile(tf.info, info) || info.Size() < tf.offset:
+		// Replaced or truncated: start over from the top.
+		tf.offset, tf.partial = 0, nil
+	}
+	tf.info = info
+	if info.Size() == tf.offset {
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	if _, err := f.Seek(tf.offset, io.SeekStart); err != nil {
+		return
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return
+	}
+	skipFirst := tf.offset > 0 && !ok // mid-file start: first line is partial
+	tf.offset += int64(len(data))
+	data = append(tf.partial, data...)
+
+	last := bytes.LastIndexByte(data, '\n')
+	if last < 0 {
+		tf.partial = data
+		return
+	}
+	tf.partial = append([]byte(nil), data[last+1:]...)
+
+	sc := bufio.NewScanner(bytes.NewReader(data[:last+1]))
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		if skipFirst {
+			skipFirst = false
+			continue
+		}
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var ev liveEvent
+		if err := json.Unmarshal(line, &ev); err != nil {
+			continue // tolerate garbage and half-written lines
+		}
+		s.apply(ev, info.ModTime())
+	}
+}
+
+// apply folds one hook event into the agent for its session.
+func (s *liveSource) apply(ev liveEvent, fallback time.Time) {
+	sid := ev.SessionID
+	if sid == "" {
+		sid = ev.Payload.SessionID
+	}
+	if sid == "" {
+		return
+	}
+	ts := parseLiveTime(ev.Timestamp, fallback)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, seen := s.agents[sid]
+	if !seen {
+		a = &Agent{
+			ID:        s.agentID(sid),
+			Name:      "Agent",
+			Status:    StatusRunning,
+			StartedAt: ts,
+			Phase:     PhaseObserve,
+		}
+		s.agents[sid] = a
+		s.ids[a.ID] = sid
+		s.order = append(s.order, sid)
+		// Link sub-agents that reported this session as parent before it appeared
+		for child, parent := range s.parents {
+			if parent == sid {
+				s.agents[child].ParentID = a.ID
+				s.changed[child] = true
+			}
+		}
+	}
+	s.changed[sid] = true
+	prev := *a
+
+	if p := ev.Payload.ParentID; p != "" && p != sid {
+		s.parents[sid] = p
+		if pa, ok := s.agents[p]; ok {
+			a.ParentID = pa.ID
+		}
+	}
+
+	if n := firstNonEmpty(ev.Payload.AgentType, ev.SourceApp); n != "" {
+		a.Name = n
+	}
+	if ev.Payload.Model != "" {
+		a.Model = ev.Payload.Model
+	}
+	if ev.Payload.WorkDir != "" && ev.Payload.WorkDir != a.WorkDir {
+		a.WorkDir = ev.Payload.WorkDir
+		delete(s.isc, sid)
+	}
+	if p, ok := parsePhase(ev.Payload.Phase); ok {
+		a.Phase = p
+		a.Progress = clamp(int(p)*14, a.Progress, 100)
+	}
+	if u := ev.Payload.Usage; u != nil {
+		if dt := ts.Sub(a.LastActTime).Seconds(); dt > 0 && !a.LastActTime.IsZero() {
+			a.TokensPerSec = float64(u.OutputTokens) / dt
+		}
+		a.TotalTokensIn += u.InputTokens
+		a.TotalTokensOut += u.OutputTokens
+		a.TotalTokensCached += u.CacheReadTokens
+	}
+
+	var entry string
+	switch ev.HookEvent {
+	case "SessionStart":
+		a.Status = StatusRunning
+		a.StartedAt = ts
+		entry = "session started"
+	case "UserPromptSubmit":
+		a.Status = StatusRunning
+		if ev.Payload.Prompt != "" {
+			a.TaskDesc = truncate(oneLine(ev.Payload.Prompt), 60)
+		}
+		entry = "prompt: " + a.TaskDesc
+	case "PreToolUse":
+		a.Status = StatusRunning
+		a.CurrentTool = ev.Payload.ToolName
+		a.LastActivity = describeTool(ev.Payload.ToolName, ev.Payload.ToolInput)
+		entry = fmt.Sprintf("%s → %s", a.CurrentTool, a.LastActivity)
+		if q, ok := askedQuestion(ev.Payload.ToolName, ev.Payload.ToolInput); ok {
+			a.Status = StatusPaused
+			q.Asked = ts
+			a.Question = q
+			entry = "awaiting input: " + truncate(oneLine(q.Text), 60)
+		}
+	case "PostToolUse":
+		a.ToolsUsed++
+		if a.Status == StatusPaused { // the question was answered
+			a.Status = StatusRunning
+		}
+		if e, failed := toolError(ev.Payload.ToolResponse); failed {
+			a.Status = StatusError
+			e.Tool, e.Time = ev.Payload.ToolName, ts
+			addError(a, e)
+			entry = fmt.Sprintf("%s failed: %s", ev.Payload.ToolName, e.Message)
+		} else {
+			entry = fmt.Sprintf("%s done", ev.Payload.ToolName)
+		}
+	case "Notification":
+		a.Status = StatusPaused
+		if a.Question.Text == "" { // AskUserQuestion already said more
+			a.Question = AgentQuestion{Text: firstNonEmpty(oneLine(ev.Payload.Message), "waiting for input"), Asked: ts}
+		}
+		entry = "awaiting input: " + truncate(oneLine(ev.Payload.Message), 60)
+	case "Stop", "SubagentStop", "SessionEnd":
+		a.Status = StatusIdle
+		a.Phase = PhaseDone
+		a.Progress = 100
+		a.TokensPerSec = 0
+		entry = strings.ToLower(ev.HookEvent)
+	default:
+		if ev.HookEvent == "" {
+			return
+		}
+		entry = ev.HookEvent
+	}
+	a.LastActTime = ts
+	trackRetry(a, prev.Status)
+	if a.Status != StatusPaused {
+		a.Question = AgentQuestion{}
+	}
+	if seen {
+		trackPhase(a, prev, ts)
+	} else {
+		beginPhases(a, ts, ev.HookEvent != "SessionStart") // joined mid-session
+	}
+
+	a.EventLog = appendEvent(a.EventLog, ts, entry)
+}
+
+// appendEvent adds a timestamped entry, keeping the log bounded.
+func appendEvent(log []string, ts time.Time, entry string) []string {
+	log = append(log, fmt.Sprintf("[%s] %s", ts.Format("15:04:05"), entry))
+	if n := int(eventLogCap.Load()); len(log) > n {
+		log = log[len(log)-n:]
+	}
+	return log
+}
+
+// agentID derives a short dashboard ID from a session ID, lengthening it
+// only when two sessions share a prefix.
+func (s *liveSource) agentID(sid string) string {
+	hex := strings.ReplaceAll(sid, "-", "")
+	for n := 4; n <= len(hex); n++ {
+		id := "pai-" + hex[:n]
+		if _, taken := s.ids[id]; !taken {
+			return id
+		}
+	}
+	return "pai-" + sid
+}
+
+// describeTool renders a tool call the way the simulated activities read,
+// e.g. "Bash: go build ./..." or "Read src/main.go".
+func describeTool(tool string, in map[string]any) string {
+	str := func(k string) string {
+		v, _ := in[k].(string)
+		return oneLine(v)
+	}
+	switch tool {
+	case "Read", "Write", "Edit", "MultiEdit", "NotebookEdit":
+		if p := str("file_path"); p != "" {
+			return tool + " " + p
+		}
+	case "Bash":
+		return "Bash: " + str("command")
+	case "Grep":
+		return fmt.Sprintf("Grep: '%s'", str("pattern"))
+	case "Glob":
+		return "Glob: " + str("pattern")
+	case "WebSearch":
+		return "WebSearch: " + str("query")
+	case "WebFetch":
+		return "WebFetch: " + str("url")
+	case "Task":
+		if t := str("subagent_type"); t != "" {
+			return "Task: spawned " + t + " agent"
+		}
+		return "Task: " + str("description")
+	case "Skill":
+		return "Skill: " + firstNonEmpty(str("skill"), str("command"))
+	}
+	return tool
+}
+
+// askedQuestion reads the question out of an AskUserQuestion call: the
+// first of its questions, with the labels of its options.
+func askedQuestion(tool string, in map[string]any) (AgentQuestion, bool) {
+	if tool != "AskUserQuestion" {
+		return AgentQuestion{}, false
+	}
+	qs, _ := in["questions"].([]any)
+	if len(qs) == 0 {
+		return AgentQuestion{}, false
+	}
+	first, _ := qs[0].(map[string]any)
+	text, _ := first["question"].(string)
+	if text = oneLine(text); text == "" {
+		return AgentQuestion{}, false
+	}
+	q := AgentQuestion{Text: text}
+	opts, _ := first["options"].([]any)
+	for _, o := range opts {
+		if o, ok := o.(map[string]any); ok {
+			if label, _ := o["label"].(string); label != "" {
+				q.Options = append(q.Options, label)
+			}
+		}
+	}
+	return q, true
+}
+
+// toolError reads a PostToolUse response that looks like an error: the
+// error text, and the exit code and standard error a shell command reports.
+func toolError(raw json.RawMessage) (AgentError, bool) {
+	if len(raw) == 0 || raw[0] != '{' {
+		return AgentError{}, false
+	}
+	var r struct {
+		IsError  bool   `json:"is_error"`
+		Error    string `json:"error"`
+		Stderr   string `json:"stderr"`
+		ExitCode int    `json:"exit_code"`
+	}
+	if json.Unmarshal(raw, &r) != nil || (!r.IsError && r.Error == "") {
+		return AgentError{}, false
+	}
+	e := AgentError{
+		Message:  truncate(oneLine(r.Error), 120),
+		ExitCode: r.ExitCode,
+		Stderr:   tailText(r.Stderr, stderrTailLines),
+	}
+	if e.Message == "" && len(e.Stderr) > 0 {
+		e.Message = truncate(e.Stderr[len(e.Stderr)-1], 120)
+	}
+	if e.Message == "" {
+		e.Message = "tool reported an error"
+	}
+	return e, true
+}
+
+// parseLiveTime accepts epoch milliseconds, epoch seconds or RFC 3339.
+func parseLiveTime(raw json.RawMessage, fallback time.Time) time.Time {
+	if len(raw) == 0 {
+		return fallback
+	}
+	if raw[0] == '"' {
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				return t
+			}
+		}
+		return fallback
+	}
+	n, err := strconv.ParseFloat(string(raw), 64)
+	if err != nil || n <= 0 {
+		return fallback
+	}
+	if n > 1e12 {
+		return time.UnixMilli(int64(n))
+	}
+	return time.Unix(int64(n), 0)
+}
+
+func parsePhase(s string) (Phase, bool) {
+	for i, n := range phaseNames {
+		if strings.EqualFold(s, n) {
+			return Phase(i), true
+		}
+	}
+	return 0, false
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
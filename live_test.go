@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parseLines feeds JSONL lines through a fresh live source the way the tailer
+// would and returns the agents it built.
+func parseLines(t *testing.T, lines ...string) []Agent {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "2026-01-02.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := newLiveSource(dir, t.TempDir())
+	s.readFile(path, false)
+	return s.Snapshot()
+}
+
+func TestLiveParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		check func(t *testing.T, agents []Agent)
+	}{
+		{
+			name: "tool call",
+			lines: []string{
+				`{"session_id":"s1","hook_event_type":"PreToolUse","timestamp":1767312000000,"payload":{"agent_type":"Engineer","model":"claude-opus-4-6","tool_name":"Bash","tool_input":{"command":"go build ./..."}}}`,
+			},
+			check: func(t *testing.T, agents []Agent) {
+				a := agents[0]
+				if a.Name != "Engineer" || a.Model != "claude-opus-4-6" || a.Status != StatusRunning {
+					t.Errorf("name %q, model %q, status %v", a.Name, a.Model, a.Status)
+				}
+				if a.CurrentTool != "Bash" || a.LastActivity != "Bash: go build ./..." {
+					t.Errorf("tool %q, activity %q", a.CurrentTool, a.LastActivity)
+				}
+				if want := time.UnixMilli(1767312000000); !a.LastActTime.Equal(want) {
+					t.Errorf("last activity at %v, want %v", a.LastActTime, want)
+				}
+			},
+		},
+		{
+			name: "garbage and blank lines are skipped",
+			lines: []string{
+				`not json`,
+				``,
+				`{"session_id":"s1","hook_event_type":"PostToolUse","payload":{"tool_name":"Read"}}`,
+				`{"session_id":"s1","hook_event_type":`,
+			},
+			check: func(t *testing.T, agents []Agent) {
+				if agents[0].ToolsUsed != 1 {
+					t.Errorf("tools used %d, want 1", agents[0].ToolsUsed)
+				}
+			},
+		},
+		{
+			name: "session ID from the payload",
+			lines: []string{
+				`{"hook_event_type":"SessionStart","payload":{"session_id":"s1"}}`,
+				`{"hook_event_type":"SessionStart","payload":{}}`,
+			},
+			check: func(t *testing.T, agents []Agent) {
+				if len(agents) != 1 {
+					t.Errorf("got %d agents, want 1", len(agents))
+				}
+			},
+		},
+		{
+			name: "failed tool",
+			lines: []string{
+				`{"session_id":"s1","hook_event_type":"PostToolUse","payload":{"tool_name":"Bash","tool_response":{"is_error":true,"stderr":"compiling\nboom","exit_code":2}}}`,
+			},
+			check: func(t *testing.T, agents []Agent) {
+				a := agents[0]
+				if a.Status != StatusError || len(a.Errors) != 1 {
+					t.Fatalf("status %v with %d errors", a.Status, len(a.Errors))
+				}
+				if e := a.Errors[0]; e.Message != "boom" || e.ExitCode != 2 || e.Tool != "Bash" {
+					t.Errorf("error %+v", e)
+				}
+			},
+		},
+		{
+			name: "question asked",
+			lines: []string{
+				`{"session_id":"s1","hook_event_type":"PreToolUse","payload":{"tool_name":"AskUserQuestion","tool_input":{"questions":[{"question":"Which  database?","options":[{"label":"Postgres"},{"label":"SQLite"}]}]}}}`,
+			},
+			check: func(t *testing.T, agents []Agent) {
+				a := agents[0]
+				if a.Status != StatusPaused || a.Question.Text != "Which database?" {
+					t.Errorf("status %v, question %q", a.Status, a.Question.Text)
+				}
+				if strings.Join(a.Question.Options, ",") != "Postgres,SQLite" {
+					t.Errorf("options %v", a.Question.Options)
+				}
+			},
+		},
+		{
+			name: "question answered",
+			lines: []string{
+				`{"session_id":"s1","hook_event_type":"PreToolUse","payload":{"tool_name":"AskUserQuestion","tool_input":{"questions":[{"question":"Which database?"}]}}}`,
+				`{"session_id":"s1","hook_event_type":"PostToolUse","payload":{"tool_name":"AskUserQuestion"}}`,
+			},
+			check: func(t *testing.T, agents []Agent) {
+				if a := agents[0]; a.Status != StatusRunning || a.Question.Text != "" {
+					t.Errorf("status %v, question %q", a.Status, a.Question.Text)
+				}
+			},
+		},
+		{
+			name: "phase and usage",
+			lines: []string{
+				`{"session_id":"s1","hook_event_type":"PostToolUse","timestamp":1767312000,"payload":{"tool_name":"Read","phase":"VERIFY","usage":{"input_tokens":100,"output_tokens":40,"cache_read_input_tokens":7}}}`,
+				`{"session_id":"s1","hook_event_type":"PostToolUse","timestamp":1767312002,"payload":{"tool_name":"Read","usage":{"input_tokens":10,"output_tokens":20}}}`,
+			},
+			check: func(t *testing.T, agents []Agent) {
+				a := agents[0]
+				if a.Phase != PhaseVerify || a.Progress != int(PhaseVerify)*14 {
+					t.Errorf("phase %v, progress %d", a.Phase, a.Progress)
+				}
+				if a.TotalTokensIn != 110 || a.TotalTokensOut != 60 || a.TotalTokensCached != 7 {
+					t.Errorf("tokens in %d, out %d, cached %d", a.TotalTokensIn, a.TotalTokensOut, a.TotalTokensCached)
+				}
+				if a.TokensPerSec != 10 {
+					t.Errorf("tok/s %v, want 10", a.TokensPerSec)
+				}
+			},
+		},
+		{
+			name: "session end",
+			lines: []string{
+				`{"session_id":"s1","hook_event_type":"PreToolUse","payload":{"tool_name":"Read"}}`,
+				`{"session_id":"s1","hook_event_type":"Stop","payload":{}}`,
+			},
+			check: func(t *testing.T, agents []Agent) {
+				if a := agents[0]; a.Status != StatusIdle || a.Phase != PhaseDone || a.Progress != 100 {
+					t.Errorf("status %v, phase %v, progress %d", a.Status, a.Phase, a.Progress)
+				}
+			},
+		},
+		{
+			name: "sub-agent seen before its parent",
+			lines: []string{
+				`{"session_id":"child","hook_event_type":"SessionStart","payload":{"parent_session_id":"parent"}}`,
+				`{"session_id":"parent","hook_event_type":"SessionStart","payload":{}}`,
+			},
+			check: func(t *testing.T, agents []Agent) {
+				if len(agents) != 2 || agents[0].ParentID != agents[1].ID {
+					t.Errorf("agents %+v", agents)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agents := parseLines(t, tt.lines...)
+			if len(agents) == 0 {
+				t.Fatal("no agents")
+			}
+			tt.check(t, agents)
+		})
+	}
+}
+
+func TestLiveReadFileFollowsAppends(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.jsonl")
+	s := newLiveSource(dir, t.TempDir())
+	write := func(data string, flag int) {
+		f, err := os.OpenFile(path, flag|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tools := func() int {
+		agents := s.Snapshot()
+		if len(agents) == 0 {
+			return 0
+		}
+		return agents[0].ToolsUsed
+	}
+	line := `{"session_id":"s1","hook_event_type":"PostToolUse","payload":{"tool_name":"Read"}}`
+
+	// A line is only read once it is terminated
+	write(line+"\n"+line[:20], os.O_CREATE|os.O_TRUNC)
+	s.readFile(path, false)
+	if got := tools(); got != 1 {
+		t.Fatalf("after a partial line: %d tool calls, want 1", got)
+	}
+	write(line[20:]+"\n", os.O_APPEND)
+	s.readFile(path, false)
+	if got := tools(); got != 2 {
+		t.Fatalf("after completing it: %d tool calls, want 2", got)
+	}
+
+	// A truncated file is read again from the top
+	write(line+"\n", os.O_TRUNC)
+	s.readFile(path, false)
+	if got := tools(); got != 3 {
+		t.Fatalf("after truncation: %d tool calls, want 3", got)
+	}
+}
+
+func TestParseLiveTime(t *testing.T) {
+	fallback := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		raw  string
+		want time.Time
+	}{
+		{``, fallback},
+		{`1767312000000`, time.UnixMilli(1767312000000)},
+		{`1767312000`, time.Unix(1767312000, 0)},
+		{`"2026-01-02T03:04:05.5Z"`, time.Date(2026, 1, 2, 3, 4, 5, 5e8, time.UTC)},
+		{`"yesterday"`, fallback},
+		{`0`, fallback},
+		{`-5`, fallback},
+		{`null`, fallback},
+	}
+	for _, tt := range tests {
+		if got := parseLiveTime(json.RawMessage(tt.raw), fallback); !got.Equal(tt.want) {
+			t.Errorf("parseLiveTime(%s) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -18,44 +18,30 @@
 //     github.com/charmbracelet/bubbles   v0.20.0
 //   )
 //
-// Run: go mod tidy && go run main.go
+// Run: go mod tidy && go run .
+//      go run . --source live [--history-dir DIR]   # tail real PAI sessions
 
 package main
 
 import (
+	"flag"
 	"fmt"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-// ---------------------------------------------------------------------------
-// Color palette — Tokyo Night, matching PAI conventions
-// ---------------------------------------------------------------------------
-
-var (
-	colorTitle   = lipgloss.Color("#7aa2f7")
-	colorRunning = lipgloss.Color("#e0af68")
-	colorIdle    = lipgloss.Color("#9ece6a")
-	colorPaused  = lipgloss.Color("#7dcfff")
-	colorError   = lipgloss.Color("#f7768e")
-	colorStopped = lipgloss.Color("#565f89")
-	colorBorder  = lipgloss.Color("#3b4261")
-	colorFg      = lipgloss.Color("#c0caf5")
-	colorDim     = lipgloss.Color("#565f89")
-	colorSelBg   = lipgloss.Color("#283457")
-	colorAccent  = lipgloss.Color("#bb9af7") // purple accent for phases
-	colorBar     = lipgloss.Color("#9ece6a") // progress bar fill
-	colorBarBg   = lipgloss.Color("#1a1b26") // progress bar empty
-)
-
 // ---------------------------------------------------------------------------
 // Domain types
 // ---------------------------------------------------------------------------
@@ -74,10 +60,6 @@ func (s AgentStatus) String() string {
 	return [...]string{"Running", "Idle", "Paused", "Error", "Stopped"}[s]
 }
 
-func (s AgentStatus) Color() lipgloss.Color {
-	return [...]lipgloss.Color{colorRunning, colorIdle, colorPaused, colorError, colorStopped}[s]
-}
-
 // Phase represents a PAI Algorithm phase (OBSERVE through LEARN).
 type Phase int
 
@@ -115,15 +97,26 @@ type Agent struct {
 	TokensPerSec  float64 // current tok/s throughput
 	TotalTokensIn int     // cumulative input tokens
 	TotalTokensOut int    // cumulative output tokens
+	TotalTokensCached int // cumulative cache-read input tokens
 	TaskDesc      string  // what this agent is working on
 	ToolsUsed     int     // total tool invocations
 	CurrentTool   string  // currently executing tool
+	WorkDir       string  // PAI WORK directory holding ISC.json, if known
+	PID           int     // OS process ID when spawned by the dashboard
+	ExitCode      int     // last process exit code (meaningful with StatusError)
+	ParentID      string  // agent that spawned this one via Task, "" for top-level
+	Errors        []AgentError // recent failures, oldest first
+	Retries       int          // restarts after a failure since the last clean finish
+	Question      AgentQuestion // what a Paused agent is waiting on; zero when nothing
+	Phases        []PhaseSpan   // time in each phase, oldest first; see phases.go
 }
 
 // ISCCriterion tracks individual success criteria with pass/fail state.
 type ISCCriterion struct {
-	Text   string
-	Passed bool
+	Text       string
+	Passed     bool
+	Evidence   string    // what the agent cited when verifying
+	VerifiedAt time.Time // zero if never verified
 }
 
 // ---------------------------------------------------------------------------
@@ -193,6 +186,31 @@ var iscPool = []string{
 	"Component renders without errors",
 }
 
+// Failures the simulation attaches to agents entering StatusError
+var errorPool = []AgentError{
+	{Tool: "Bash", Message: "npm run test: 3 tests failed", ExitCode: 1,
+		Stderr: []string{"FAIL src/auth/middleware.test.ts", "  ● rejects expired tokens", "    Expected: 401", "    Received: 200"}},
+	{Tool: "Bash", Message: "go build ./... failed", ExitCode: 2,
+		Stderr: []string{"# pai/api", "api/routes.go:42:9: undefined: authMiddleware"}},
+	{Tool: "Bash", Message: "migration script not executable", ExitCode: 126,
+		Stderr: []string{"sh: ./scripts/migrate.sh: Permission denied"}},
+	{Tool: "Bash", Message: "killed: out of memory", ExitCode: 137,
+		Stderr: []string{"fatal error: runtime: out of memory", "goroutine 1 [running]:"}},
+	{Tool: "WebFetch", Message: "request timed out after 30s"},
+	{Tool: "Edit", Message: "old_string not found in config/database.yaml"},
+	{Tool: "Read", Message: "file does not exist: src/auth/session.ts"},
+	{Message: "model API rate limit exceeded (429)"},
+}
+
+var questionPool = []AgentQuestion{
+	{Text: "Which database should the migration target?", Options: []string{"PostgreSQL", "SQLite", "Both"}},
+	{Text: "The tests touch config/production.yaml. Continue anyway?", Options: []string{"Yes", "No, use fixtures"}},
+	{Text: "Run scripts/migrate.sh against staging?", Options: []string{"Approve", "Deny"}},
+	{Text: "Two JWT libraries fit. Which one should I use?", Options: []string{"golang-jwt/jwt", "go-jose/go-jose"}},
+	{Text: "What should the new endpoint be called?"},
+	{Text: "How should the rate limiter behave when Redis is down?"},
+}
+
 // ---------------------------------------------------------------------------
 // Helpers
 // ---------------------------------------------------------------------------
@@ -238,7 +256,7 @@ func clamp(v, lo, hi int) int {
 func pickRand[T any](sl []T) T { return sl[rand.Intn(len(sl))] }
 
 // renderProgressBar draws a visual bar like ████░░░░ 45%
-func renderProgressBar(pct, width int) string {
+func renderProgressBar(pct, width int, th Theme) string {
 	if width < 8 {
 		width = 8
 	}
@@ -249,76 +267,15 @@ func renderProgressBar(pct, width int) string {
 	filled := barW * pct / 100
 	empty := barW - filled
 
-	fillStyle := lipgloss.NewStyle().Foreground(colorBar)
-	emptyStyle := lipgloss.NewStyle().Foreground(colorBarBg)
-	pctStyle := lipgloss.NewStyle().Foreground(colorFg)
+	fillStyle := lipgloss.NewStyle().Foreground(th.Bar)
+	emptyStyle := lipgloss.NewStyle().Foreground(th.BarBg)
+	pctStyle := lipgloss.NewStyle().Foreground(th.Fg)
 
 	bar := fillStyle.Render(strings.Repeat("█", filled)) +
 		emptyStyle.Render(strings.Repeat("░", empty))
 	return bar + pctStyle.Render(fmt.Sprintf(" %3d%%", pct))
 }
 
-func makeAgent() Agent {
-	name := pickRand(agentNames)
-	model := pickRand(models)
-	now := time.Now()
-	status := AgentStatus(rand.Intn(3)) // Running, Idle, or Paused
-	phase := Phase(rand.Intn(7))
-
-	// ISC criteria with random pass/fail
-	iscCount := 3 + rand.Intn(4)
-	isc := make([]ISCCriterion, 0, iscCount)
-	for i := 0; i < iscCount; i++ {
-		isc = append(isc, ISCCriterion{
-			Text:   pickRand(iscPool),
-			Passed: rand.Float32() < 0.6,
-		})
-	}
-
-	// Seed event log
-	log := make([]string, 0, 8)
-	for i := 0; i < 4+rand.Intn(5); i++ {
-		t := now.Add(-time.Duration(rand.Intn(300)) * time.Second)
-		tool := pickRand(toolNames)
-		log = append(log, fmt.Sprintf("[%s] %s: %s", t.Format("15:04:05"), tool, pickRand(activities)))
-	}
-
-	// Token throughput based on model
-	tokRange := modelTokRanges[model]
-	tokps := tokRange[0] + rand.Float64()*(tokRange[1]-tokRange[0])
-
-	// Progress tied to phase
-	baseProgress := int(phase) * 14 // ~14% per phase
-	progress := clamp(baseProgress+rand.Intn(14), 0, 100)
-	if status == StatusIdle {
-		progress = 100
-		phase = PhaseDone
-	}
-	if status == StatusStopped {
-		progress = 0
-	}
-
-	return Agent{
-		ID:             "pai-" + randHex4(),
-		Name:           name,
-		Status:         status,
-		StartedAt:      now.Add(-time.Duration(rand.Intn(600)) * time.Second),
-		LastActTime:    now.Add(-time.Duration(rand.Intn(20)) * time.Second),
-		LastActivity:   pickRand(activities),
-		Model:          model,
-		ISCItems:       isc,
-		EventLog:       log,
-		Phase:          phase,
-		Progress:       progress,
-		TokensPerSec:   tokps,
-		TotalTokensIn:  5000 + rand.Intn(50000),
-		TotalTokensOut: 1000 + rand.Intn(20000),
-		TaskDesc:       pickRand(taskDescs),
-		ToolsUsed:      rand.Intn(40),
-		CurrentTool:    pickRand(toolNames),
-	}
-}
-
 // ---------------------------------------------------------------------------
 // Bubble Tea messages
 // ---------------------------------------------------------------------------
@@ -326,12 +283,12 @@ func makeAgent() Agent {
 type tickMsg time.Time
 type loadedMsg struct{}
 
-func tickCmd() tea.Cmd {
-	return tea.Tick(2*time.Second, func(t time.Time) tea.Msg { return tickMsg(t) })
+func tickCmd(every time.Duration) tea.Cmd {
+	return tea.Tick(every, func(t time.Time) tea.Msg { return tickMsg(t) })
 }
 
-func loadCmd() tea.Cmd {
-	return tea.Tick(1500*time.Millisecond, func(_ time.Time) tea.Msg { return loadedMsg{} })
+func loadCmd(delay time.Duration) tea.Cmd {
+	return tea.Tick(delay, func(_ time.Time) tea.Msg { return loadedMsg{} })
 }
 
 // ---------------------------------------------------------------------------
@@ -339,28 +296,100 @@ func loadCmd() tea.Cmd {
 // ---------------------------------------------------------------------------
 
 type keyMap struct {
-	Up      key.Binding
-	Down    key.Binding
-	Enter   key.Binding
+	Up       key.Binding
+	Down     key.Binding
+	Top      key.Binding
+	Bottom   key.Binding
+	HalfDown key.Binding
+	HalfUp   key.Binding
+	Enter    key.Binding
 	Refresh key.Binding
 	Toggle  key.Binding
+	Pause   key.Binding
+	New     key.Binding
+	Sort    key.Binding
+	Reverse key.Binding
+	Filter  key.Binding
+	Tree    key.Binding
+	Alerts  key.Binding
+	Theme   key.Binding
+	Help    key.Binding
+	Log     key.Binding
+	Answer  key.Binding
+	Queue   key.Binding
+	Overview key.Binding
 	Quit    key.Binding
+	// Tree controls, enabled only in tree view
+	Collapse key.Binding
+	Expand   key.Binding
+	// Clears the active filter; enabled only while one is set
+	ClearFilter key.Binding
+	// Replay controls, enabled only when the source is a Player
+	Play     key.Binding
+	Slower   key.Binding
+	Faster   key.Binding
+	SeekBack key.Binding
+	SeekFwd  key.Binding
 }
 
 func (k keyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Up, k.Down, k.Enter, k.Refresh, k.Toggle, k.Quit}
+	return []key.Binding{k.Up, k.Down, k.Enter, k.Toggle, k.Pause, k.New, k.Filter, k.ClearFilter, k.Tree, k.Overview,
+		k.Play, k.Slower, k.Faster, k.SeekBack, k.SeekFwd, k.Help, k.Quit}
+}
+
+// FullHelp groups every binding for the ? overlay; see helpGroups.
+func (k keyMap) FullHelp() [][]key.Binding {
+	groups := make([][]key.Binding, len(helpGroups(k)))
+	for i, g := range helpGroups(k) {
+		groups[i] = g.bindings
+	}
+	return groups
 }
-func (k keyMap) FullHelp() [][]key.Binding { return [][]key.Binding{k.ShortHelp()} }
 
-var keys = keyMap{
-	Up:      key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
-	Down:    key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
-	Enter:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("⏎", "detail")),
-	Refresh: key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
-	Toggle:  key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "start/stop")),
-	Quit:    key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Up:       key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+		Down:     key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+		Top:      key.NewBinding(key.WithKeys("g", "home"), key.WithHelp("g", "first (Ng: row N)")),
+		Bottom:   key.NewBinding(key.WithKeys("G", "end"), key.WithHelp("G", "last (NG: row N)")),
+		HalfDown: key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "half page down")),
+		HalfUp:   key.NewBinding(key.WithKeys("ctrl+u"), key.WithHelp("ctrl+u", "half page up")),
+		Enter:    key.NewBinding(key.WithKeys("enter"), key.WithHelp("⏎", "detail")),
+		Refresh:  key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
+		Toggle:   key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "start/stop")),
+		Pause:    key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "pause/resume")),
+		New:      key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new agent")),
+		Sort:     key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "sort")),
+		Reverse:  key.NewBinding(key.WithKeys("O"), key.WithHelp("O", "reverse")),
+		Filter:   key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
+		Tree:     key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "tree")),
+		Alerts:   key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "alerts")),
+		Theme:    key.NewBinding(key.WithKeys("T"), key.WithHelp("T", "theme")),
+		Help:     key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
+		Log:      key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "event log")),
+		Answer:   key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "answer")),
+		Queue:    key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "waiting queue")),
+		Overview: key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "overview")),
+		Quit:     key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+
+		ClearFilter: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "clear filter"), key.WithDisabled()),
+		Collapse:    key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "collapse"), key.WithDisabled()),
+		Expand:      key.NewBinding(key.WithKeys("l"), key.WithHelp("l", "expand"), key.WithDisabled()),
+
+		Play:     key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "play/pause"), key.WithDisabled()),
+		Slower:   key.NewBinding(key.WithKeys("-"), key.WithHelp("-", "slower"), key.WithDisabled()),
+		Faster:   key.NewBinding(key.WithKeys("+", "="), key.WithHelp("+", "faster"), key.WithDisabled()),
+		SeekBack: key.NewBinding(key.WithKeys("left"), key.WithHelp("←", "-10s"), key.WithDisabled()),
+		SeekFwd:  key.NewBinding(key.WithKeys("right"), key.WithHelp("→", "+10s"), key.WithDisabled()),
+	}
 }
 
+// keys is the active keymap: the defaults with any [keys] from the config.
+var keys = defaultKeyMap()
+
+// replaySeekStep is how far one seek key press moves playback.
+const replaySeekStep = 10 * time.Second
+
 // ---------------------------------------------------------------------------
 // Model
 // ---------------------------------------------------------------------------
@@ -376,29 +405,97 @@ type model struct {
 	width       int
 	height      int
 	totalTicks  int
+	source      AgentSource
+	notice      string         // last control error, shown in the status bar
+	confirm     *pendingAction // awaiting y/n before it is sent to the source
+	spawn       *spawnForm     // open spawn dialog, nil when closed
+	answering   *answerForm    // open input panel, nil when closed
+	queueOpen   bool           // waiting queue pane
+	pricing     Pricing
+	pricesFlag  *Pricing    // from --prices; wins over the config's [prices]
+	alertsFlag  *AlertRules // from --alert-rules; wins over [alerts]
+	notifyFlag  *notifyConfig // from --notify; wins over [[notify.rules]]
+	sortBy      sortKey
+	sortDesc    bool
+	budgetHits  map[string]bool // agents already auto-paused for their budget
+	lastSpawn   SpawnRequest   // pre-fills the next spawn dialog
+	filter      agentFilter    // applied by rows()
+	filtering   bool           // filter prompt has focus
+	filterInput textinput.Model
+	filterErr   string // why the text in the prompt does not parse
+	treeView    bool
+	collapsed   map[string]bool // tree nodes with their children hidden
+	history     map[string]*agentHistory
+	alerts      *alertEngine
+	alertsOpen  bool
+	notify      *notifier
+	config      Config
+	configPath  string      // watched for changes; "" when there is no file
+	configInfo  os.FileInfo // stat of the config as loaded
+	theme       Theme
+	themePick   string      // theme chosen with T, kept across config reloads
+	darkBg      bool        // terminal background, detected once at startup
+	helpOpen    bool        // full-screen keybinding overlay
+	count       int         // pending numeric prefix, 0 when none
+	offset      int         // first table row on screen
+	pager       *logPager   // open event log pager, nil when closed
+	events      *eventStore // disk-backed event history; nil without --event-log-dir
+	recorder    *recordSource // nil without --record
+	lastClick   time.Time   // previous left click on a row, for double-clicks
+	restarts    *supervisor // restart policies: pending restarts and breakers
+	phases      *phaseStats   // average time per phase by agent type
+	overview    bool          // fleet overview screen instead of the table
+	fleet       *fleetHistory // fleet-wide throughput for the overview
+	termSeq     string        // bell and OSC sequences to draw with the next frame
+	termSeqGen  int           // bumped per termSeqMsg, so only the last hold clears
 }
 
-func initialModel() model {
-	sp := spinner.New()
-	sp.Spinner = spinner.MiniDot
-	sp.Style = lipgloss.NewStyle().Foreground(colorTitle)
+// pendingAction is a control action held back for confirmation.
+type pendingAction struct {
+	id     string
+	action ControlAction
+	prompt string
+}
 
-	agents := make([]Agent, 0, 10)
-	for i := 0; i < 10; i++ {
-		agents = append(agents, makeAgent())
+func newModel(src AgentSource) model {
+	_, replay := playerOf(src)
+	for _, b := range []*key.Binding{&keys.Play, &keys.Slower, &keys.Faster, &keys.SeekBack, &keys.SeekFwd} {
+		b.SetEnabled(replay)
 	}
+	keys.ClearFilter.SetEnabled(false)
+	keys.Collapse.SetEnabled(false)
+	keys.Expand.SetEnabled(false)
+
+	sp := spinner.New()
+	sp.Spinner = spinner.MiniDot
+	sp.Style = lipgloss.NewStyle().Foreground(themes[0].Title)
 
 	return model{
-		agents:      agents,
+		agents:      src.Snapshot(),
 		loading:     true,
 		spinner:     sp,
 		help:        help.New(),
 		lastRefresh: time.Now(),
+		source:      src,
+		pricing:     defaultPricing(),
+		theme:       themes[0],
+		darkBg:      true,
+		budgetHits:  map[string]bool{},
+		collapsed:   map[string]bool{},
+		history:     map[string]*agentHistory{},
+		alerts:      newAlertEngine(defaultAlertRules()),
+		notify:      newNotifier(nil),
+		restarts:    newSupervisor(),
+		phases:      newPhaseStats(),
+		fleet:       &fleetHistory{},
+		config:      defaultConfig(),
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	return tea.Batch(m.spinner.Tick, loadCmd(), tickCmd())
+	return tea.Batch(m.spinner.Tick, loadCmd(time.Duration(m.config.LoadDelay)),
+		tickCmd(time.Duration(m.config.TickInterval)), waitForUpdate(m.source),
+		watchConfig(m.configPath, m.configInfo))
 }
 
 // ---------------------------------------------------------------------------
@@ -411,6 +508,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.help.Width = msg.Width
+		if m.pager != nil {
+			m.resizePager()
+		}
+		m.scrollToCursor()
 		return m, nil
 
 	case loadedMsg:
@@ -418,172 +519,367 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tickMsg:
-		m.simulateTick()
-		m.lastRefresh = time.Now()
 		m.totalTicks++
-		return m, tickCmd()
+		m.sampleHistory()
+		m.alerts.evaluate(m.agents, m.history, time.Now())
+		if err := m.events.takeErr(); err != nil {
+			m.notice = err.Error()
+		}
+		if err := m.recorder.takeErr(); err != nil {
+			m.notice = err.Error()
+		}
+		return m, tickCmd(time.Duration(m.config.TickInterval))
+
+	case sourceUpdateMsg:
+		m.applyUpdate(AgentUpdate(msg))
+		m.alerts.evaluate(m.agents, m.history, time.Now())
+		m.enforceBudgets()
+		m.lastRefresh = time.Now()
+		var pagerCmd tea.Cmd
+		if m.pager != nil {
+			pagerCmd = m.pagerUpdated()
+		}
+		return m, tea.Batch(waitForUpdate(m.source), m.notify.flush(), m.restarts.flush(), pagerCmd)
+
+	case restartMsg:
+		if !m.restarts.due(msg) {
+			return m, nil
+		}
+		if err := m.source.Control(msg.id, ActionStart); err != nil {
+			m.notice = fmt.Sprintf("restart %s: %v", msg.id, err)
+		}
+		return m, nil
+
+	case configPollMsg:
+		return m, watchConfig(m.configPath, msg.info)
+
+	case configMsg:
+		if msg.err != nil {
+			m.notice = msg.err.Error()
+			return m, watchConfig(m.configPath, msg.info)
+		}
+		changed := sourceChanged(m.config.Source, msg.cfg.Source)
+		m.applyConfig(msg.cfg)
+		m.notice = "config reloaded"
+		if len(changed) > 0 {
+			m.notice += "; restart to apply source." + strings.Join(changed, ", source.")
+		}
+		return m, watchConfig(m.configPath, msg.info)
+
+	case notifyErrMsg:
+		m.notice = msg.err.Error()
+		return m, nil
+
+	case termSeqMsg:
+		m.termSeq += string(msg)
+		m.termSeqGen++
+		gen := m.termSeqGen
+		return m, tea.Tick(termSeqHold, func(time.Time) tea.Msg { return termSeqDoneMsg{gen} })
+
+	case termSeqDoneMsg:
+		if msg.gen == m.termSeqGen {
+			m.termSeq = ""
+		}
+		return m, nil
 
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
 		return m, cmd
 
+	case logLoadedMsg:
+		m.pagerLoaded(msg)
+		return m, nil
+
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
+
 	case tea.KeyMsg:
+		if m.pager != nil {
+			return m.updatePager(msg)
+		}
+		if m.spawn != nil {
+			return m.updateSpawn(msg)
+		}
+		if m.answering != nil {
+			return m.updateAnswer(msg)
+		}
+		if m.filtering {
+			return m.updateFilter(msg)
+		}
+		if m.confirm != nil {
+			p := m.confirm
+			m.confirm = nil
+			if msg.String() == "y" || msg.String() == "Y" {
+				m.control(p.id, p.action)
+			} else {
+				m.notice = ""
+			}
+			return m, nil
+		}
+		if m.helpOpen {
+			switch {
+			case msg.Type == tea.KeyCtrlC:
+				return m, tea.Quit
+			case key.Matches(msg, keys.Help, keys.Quit), msg.Type == tea.KeyEsc:
+				m.helpOpen = false
+			}
+			return m, nil
+		}
+		if m.overview {
+			return m.updateOverview(msg)
+		}
+		if m.takeCount(msg) {
+			return m, nil
+		}
+		n, counted := m.popCount()
 		switch {
 		case key.Matches(msg, keys.Quit):
 			return m, tea.Quit
+		case key.Matches(msg, keys.Help):
+			m.helpOpen = true
 		case key.Matches(msg, keys.Up):
-			if m.cursor > 0 {
-				m.cursor--
-			}
+			m.moveCursor(-n)
 		case key.Matches(msg, keys.Down):
-			if m.cursor < len(m.agents)-1 {
-				m.cursor++
+			m.moveCursor(n)
+		case key.Matches(msg, keys.HalfUp):
+			m.moveCursor(-n * m.halfPage())
+		case key.Matches(msg, keys.HalfDown):
+			m.moveCursor(n * m.halfPage())
+		case key.Matches(msg, keys.Top):
+			m.cursor = 0
+			if counted {
+				m.moveCursor(n - 1)
 			}
+		case key.Matches(msg, keys.Bottom):
+			m.cursor = max(len(m.rows())-1, 0)
+			if counted {
+				m.cursor = 0
+				m.moveCursor(n - 1)
+			}
+		case key.Matches(msg, keys.Filter):
+			m.filtering = true
+			m.filterInput = newFilterInput(m.filter.text)
+		case key.Matches(msg, keys.ClearFilter):
+			m.setFilter(agentFilter{})
+		case key.Matches(msg, keys.Tree):
+			m.toggleTree()
+		case key.Matches(msg, keys.Alerts):
+			m.alertsOpen = !m.alertsOpen
+		case key.Matches(msg, keys.Queue):
+			m.queueOpen = !m.queueOpen
+		case key.Matches(msg, keys.Overview):
+			m.overview = true
+		case key.Matches(msg, keys.Answer):
+			m.openAnswer()
+		case key.Matches(msg, keys.Log):
+			return m, m.openPager()
+		case key.Matches(msg, keys.Theme):
+			t := nextTheme(m.theme)
+			m.themePick = t.Name
+			m.setTheme(t)
+			m.notice = "theme: " + m.theme.title()
+		case key.Matches(msg, keys.Collapse):
+			m.collapse()
+		case key.Matches(msg, keys.Expand):
+			m.expand()
+		case key.Matches(msg, keys.Sort):
+			m.cycleSort(false)
+		case key.Matches(msg, keys.Reverse):
+			m.cycleSort(true)
 		case key.Matches(msg, keys.Enter):
-			if len(m.agents) > 0 {
+			if _, ok := m.selected(); ok {
 				m.detailOpen = !m.detailOpen
 			}
 		case key.Matches(msg, keys.Refresh):
-			m.simulateTick()
-			m.lastRefresh = time.Now()
+			m.source.Refresh()
 		case key.Matches(msg, keys.Toggle):
-			if len(m.agents) > 0 {
-				a := &m.agents[m.cursor]
-				if a.Status == StatusStopped {
-					a.Status = StatusRunning
-					a.StartedAt = time.Now()
-					a.Phase = PhaseObserve
-					a.Progress = 0
-				} else {
-					a.Status = StatusStopped
-					a.TokensPerSec = 0
+			if a, ok := m.selected(); ok {
+				switch a.Status {
+				case StatusStopped, StatusIdle, StatusError:
+					m.control(a.ID, ActionStart)
+				case StatusRunning:
+					m.confirm = &pendingAction{
+						id: a.ID, action: ActionStop,
+						prompt: fmt.Sprintf("Stop %s (%s)? y/n", a.ID, a.Name),
+					}
+				default:
+					m.control(a.ID, ActionStop)
+				}
+			}
+		case key.Matches(msg, keys.Play, keys.Slower, keys.Faster, keys.SeekBack, keys.SeekFwd):
+			m.controlPlayback(msg)
+		case key.Matches(msg, keys.New):
+			if _, ok := m.source.(Spawner); !ok {
+				m.notice = "spawn: " + errUnsupported.Error()
+				break
+			}
+			f := newSpawnForm(m.lastSpawn)
+			f.setFocus(fieldType)
+			m.spawn = &f
+			m.notice = ""
+		case key.Matches(msg, keys.Pause):
+			if a, ok := m.selected(); ok {
+				switch a.Status {
+				case StatusRunning:
+					m.control(a.ID, ActionPause)
+				case StatusPaused:
+					m.control(a.ID, ActionResume)
+				default:
+					m.notice = fmt.Sprintf("%s is %s", a.ID, strings.ToLower(a.Status.String()))
 				}
 			}
 		}
+		m.scrollToCursor()
 	}
 	return m, nil
 }
 
-// simulateTick mutates agent state every 2 seconds for real-time feel.
-func (m *model) simulateTick() {
-	now := time.Now()
-
-	// Transition 1-2 agent statuses
-	transitions := 1 + rand.Intn(2)
-	for t := 0; t < transitions && len(m.agents) > 0; t++ {
-		idx := rand.Intn(len(m.agents))
-		a := &m.agents[idx]
-		switch a.Status {
-		case StatusRunning:
-			if rand.Float32() < 0.15 {
-				a.Status = []AgentStatus{StatusIdle, StatusPaused, StatusError}[rand.Intn(3)]
-				if a.Status == StatusIdle {
-					a.Phase = PhaseDone
-					a.Progress = 100
-					a.TokensPerSec = 0
-				}
-			}
-		case StatusIdle:
-			if rand.Float32() < 0.3 {
-				a.Status = StatusRunning
-				a.Phase = PhaseObserve
-				a.Progress = 0
-				a.TaskDesc = pickRand(taskDescs)
-			}
-		case StatusPaused:
-			if rand.Float32() < 0.4 {
-				a.Status = StatusRunning
-			}
-		case StatusError:
-			if rand.Float32() < 0.3 {
-				a.Status = StatusRunning
-				a.Phase = PhaseObserve
-				a.Progress = 0
-			}
+// updateSpawn routes keys to the open spawn dialog and submits it.
+func (m model) updateSpawn(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f, res, cmd := m.spawn.Update(msg)
+	switch res {
+	case formCancel:
+		m.lastSpawn = f.request() // reopening picks up where this left off
+		m.spawn = nil
+	case formSubmit:
+		req := f.request()
+		a, err := m.source.(Spawner).Spawn(req)
+		if err != nil {
+			f.err = err.Error()
+			m.spawn = &f
+			break
 		}
+		m.lastSpawn = req
+		m.spawn = nil
+		m.applyUpdate(AgentUpdate{Agents: []Agent{a}})
+		m.keepSelection(a.ID)
+	default:
+		m.spawn = &f
 	}
+	return m, cmd
+}
 
-	// Update all running agents: advance phase, progress, tokens, activity
-	for i := range m.agents {
-		a := &m.agents[i]
-		if a.Status != StatusRunning {
-			continue
+// controlPlayback handles the replay keys.
+func (m *model) controlPlayback(msg tea.KeyMsg) {
+	pl, ok := playerOf(m.source)
+	if !ok {
+		return
+	}
+	st := pl.Playback()
+	speedIdx := 0
+	for i, sp := range replaySpeeds {
+		if sp <= st.Speed {
+			speedIdx = i
 		}
+	}
+	switch {
+	case key.Matches(msg, keys.Play):
+		pl.SetPaused(!st.Paused)
+	case key.Matches(msg, keys.Slower):
+		pl.SetSpeed(replaySpeeds[max(speedIdx-1, 0)])
+	case key.Matches(msg, keys.Faster):
+		pl.SetSpeed(replaySpeeds[min(speedIdx+1, len(replaySpeeds)-1)])
+	case key.Matches(msg, keys.SeekBack):
+		pl.Seek(st.Position - replaySeekStep)
+	case key.Matches(msg, keys.SeekFwd):
+		pl.Seek(st.Position + replaySeekStep)
+	}
+}
 
-		// Advance phase probabilistically
-		if a.Phase < PhaseDone && rand.Float32() < 0.25 {
-			a.Phase++
-			if a.Phase == PhaseDone {
-				a.Status = StatusIdle
-				a.Progress = 100
-				a.TokensPerSec = 0
-				continue
-			}
+// enforceBudgets pauses running agents that have spent past their budget,
+// once per agent, when the price file asks for it.
+func (m *model) enforceBudgets() {
+	if !m.pricing.PauseOverBudget {
+		return
+	}
+	for _, a := range m.agents {
+		if a.Status != StatusRunning || m.budgetHits[a.ID] || !m.pricing.OverBudget(a) {
+			continue
 		}
-
-		// Progress: advance toward phase-appropriate percentage
-		targetPct := clamp(int(a.Phase+1)*14+rand.Intn(5), 0, 99)
-		if a.Progress < targetPct {
-			a.Progress += 1 + rand.Intn(4)
-			if a.Progress > targetPct {
-				a.Progress = targetPct
-			}
+		m.budgetHits[a.ID] = true
+		m.control(a.ID, ActionPause)
+		if m.notice == "" {
+			m.notice = fmt.Sprintf("%s paused: over %s budget", a.ID, fmtCost(m.pricing.Budget(a)))
 		}
+	}
+}
 
-		// Token throughput: fluctuate around model baseline
-		tokRange := modelTokRanges[a.Model]
-		base := (tokRange[0] + tokRange[1]) / 2
-		jitter := (rand.Float64() - 0.5) * (tokRange[1] - tokRange[0]) * 0.6
-		a.TokensPerSec = base + jitter
-		if a.TokensPerSec < 0 {
-			a.TokensPerSec = tokRange[0]
-		}
+// control sends an action to the source and reports failure in the status bar.
+func (m *model) control(id string, action ControlAction) {
+	m.notice = ""
+	if err := m.source.Control(id, action); err != nil {
+		m.notice = fmt.Sprintf("%s %s: %v", action, id, err)
+	}
+}
 
-		// Accumulate tokens (simulate ~2 seconds of throughput)
-		newOut := int(a.TokensPerSec * 2)
-		a.TotalTokensOut += newOut
-		a.TotalTokensIn += newOut * (2 + rand.Intn(3)) // input usually 2-4x output
+// restartPolicy is the [restart] policy for an agent, or never when the
+// source cannot start it again.
+func (m model) restartPolicy(a Agent) restartPolicy {
+	p := restartFor(m.config.Restart, a.Name)
+	if rs, ok := m.source.(Restarter); !ok || !rs.CanRestart(a.ID) {
+		p.Policy = policyNever
+	}
+	return p
+}
 
-		// Activity & tool usage
-		a.CurrentTool = pickRand(toolNames)
-		a.LastActivity = pickRand(activities)
-		a.LastActTime = now.Add(-time.Duration(rand.Intn(3)) * time.Second)
-		a.ToolsUsed++
-		entry := fmt.Sprintf("[%s] %s → %s",
-			now.Format("15:04:05"), a.CurrentTool, a.LastActivity)
-		a.EventLog = append(a.EventLog, entry)
-		if len(a.EventLog) > 20 {
-			a.EventLog = a.EventLog[len(a.EventLog)-20:]
+// applyUpdate upserts agents by ID, keeping existing rows in place and
+// appending new ones at the bottom, then drops removed agents.
+func (m *model) applyUpdate(u AgentUpdate) {
+	cur, _ := m.selected()
+	now := time.Now()
+	_, replay := playerOf(m.source) // seeking would count phase spans again
+	idx := make(map[string]int, len(m.agents))
+	for i, a := range m.agents {
+		idx[a.ID] = i
+	}
+	for _, a := range u.Agents {
+		if i, ok := idx[a.ID]; ok {
+			m.alerts.observe(m.agents[i], a, now)
+			m.notify.observe(m.agents[i], a, now)
+			m.restarts.observe(m.agents[i], a, m.restartPolicy(a), now)
+			if !replay {
+				m.phases.observe(m.agents[i], a)
+			}
+			m.events.observe(m.agents[i], a)
+			m.agents[i] = a
+			continue
 		}
-
-		// Occasionally flip an ISC criterion
-		if rand.Float32() < 0.2 && len(a.ISCItems) > 0 {
-			idx := rand.Intn(len(a.ISCItems))
-			a.ISCItems[idx].Passed = !a.ISCItems[idx].Passed
+		m.events.observe(Agent{}, a)
+		if !replay {
+			m.phases.observe(Agent{}, a)
 		}
+		idx[a.ID] = len(m.agents)
+		m.agents = append(m.agents, a)
 	}
-
-	// Occasionally spawn or garbage-collect
-	if rand.Float32() < 0.12 && len(m.agents) < 14 {
-		m.agents = append(m.agents, makeAgent())
-	}
-	if rand.Float32() < 0.06 && len(m.agents) > 6 {
-		idx := rand.Intn(len(m.agents))
-		if m.agents[idx].Status == StatusStopped {
-			m.agents = append(m.agents[:idx], m.agents[idx+1:]...)
-			if m.cursor >= len(m.agents) {
-				m.cursor = clamp(len(m.agents)-1, 0, len(m.agents))
+	if len(u.Removed) > 0 {
+		gone := make(map[string]bool, len(u.Removed))
+		for _, id := range u.Removed {
+			gone[id] = true
+			m.restarts.forget(id)
+		}
+		kept := m.agents[:0]
+		for _, a := range m.agents {
+			if !gone[a.ID] {
+				kept = append(kept, a)
 			}
 		}
+		m.agents = kept
 	}
+	m.keepSelection(cur.ID)
 }
 
 // ---------------------------------------------------------------------------
 // View
 // ---------------------------------------------------------------------------
 
+// View draws the frame, led by any bell and OSC sequences notifications
+// are waiting to send.
 func (m model) View() string {
+	return m.termSeq + m.view()
+}
+
+func (m model) view() string {
 	w := m.width
 	if w == 0 {
 		w = 140
@@ -594,125 +890,334 @@ func (m model) View() string {
 		s := lipgloss.NewStyle().
 			Width(w).Height(m.height).
 			Align(lipgloss.Center, lipgloss.Center).
-			Foreground(colorTitle)
+			Foreground(m.theme.Title)
 		return s.Render(m.spinner.View() + "  Connecting to PAI orchestration layer...")
 	}
 
+	// --- Event log pager ---
+	if m.pager != nil {
+		return m.renderPager(w)
+	}
+
+	// --- Help overlay ---
+	if m.helpOpen {
+		h := m.height
+		if h == 0 {
+			h = 24
+		}
+		return m.renderHelp(w, h)
+	}
+
+	// --- Spawn dialog (modal) ---
+	if m.spawn != nil {
+		h := m.height
+		if h == 0 {
+			h = 24
+		}
+		return lipgloss.Place(w, h, lipgloss.Center, lipgloss.Center, m.spawn.View(w, m.theme))
+	}
+
+	// --- Input panel (modal) ---
+	if m.answering != nil {
+		h := m.height
+		if h == 0 {
+			h = 24
+		}
+		return lipgloss.Place(w, h, lipgloss.Center, lipgloss.Center, m.answering.View(w, m.theme))
+	}
+
+	// --- Fleet overview ---
+	if m.overview {
+		return m.renderOverview(w)
+	}
+
 	// --- Empty ---
 	if len(m.agents) == 0 {
 		s := lipgloss.NewStyle().
 			Width(w).Height(m.height).
 			Align(lipgloss.Center, lipgloss.Center).
-			Foreground(colorDim)
+			Foreground(m.theme.Dim)
 		return s.Render("No agents active. Press 'n' to spawn a new agent.")
 	}
 
-	var sections []string
+	sections := m.topSections(w)
+
+	// --- Table ---
+	bottom, rows := m.layout(w, sections)
+	sections = append(sections, m.renderTable(w, rows))
+	sections = append(sections, bottom...)
+
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
 
+// topSections are the title bar and, while it is open, the filter prompt.
+func (m model) topSections(w int) (top []string) {
 	// --- Title bar ---
 	titleStyle := lipgloss.NewStyle().
-		Bold(true).Foreground(colorTitle).
+		Bold(true).Foreground(m.theme.Title).
 		BorderStyle(lipgloss.RoundedBorder()).
-		BorderForeground(colorBorder).
+		BorderForeground(m.theme.Border).
 		Padding(0, 2).Width(w - 2).
 		Align(lipgloss.Center)
-	sections = append(sections, titleStyle.Render(
-		fmt.Sprintf("⚡ PAI Agent Dashboard v0.2.0  │  %d agents  │  %s",
-			len(m.agents), time.Now().Format("15:04:05"))))
+	count := fmt.Sprintf("%d agents", len(m.agents))
+	if m.filter.active() {
+		count = fmt.Sprintf("%d/%d agents  │  filter: %s", len(m.filtered()), len(m.agents), m.filter.text)
+	}
+	top = append(top, titleStyle.Render(
+		fmt.Sprintf("⚡ PAI Agent Dashboard v0.2.0  │  %s  │  %s",
+			count, time.Now().Format("15:04:05"))))
+
+	// --- Filter prompt ---
+	if m.filtering {
+		prompt := " " + m.filterInput.View()
+		if m.filterErr != "" {
+			prompt += "  " + lipgloss.NewStyle().Foreground(m.theme.Error).Render(m.filterErr)
+		}
+		top = append(top, prompt)
+	}
+	return top
+}
 
-	// --- Table ---
-	sections = append(sections, m.renderTable(w))
+// minTableRows is as far as the table shrinks before the detail pane is cut.
+const minTableRows = 3
 
-	// --- Detail pane ---
-	if m.detailOpen && m.cursor < len(m.agents) {
-		sections = append(sections, m.renderDetail(w))
+// layout renders the sections below the table and works out how many agent
+// rows fit between them and top. Without a known height (screenshots) every
+// row fits.
+func (m model) layout(w int, top []string) (bottom []string, rows int) {
+	var before, after []string // around the detail pane
+
+	// --- Waiting queue ---
+	if m.queueOpen {
+		before = append(before, m.renderQueue(w, 6))
+	}
+
+	// --- Alert log ---
+	if m.alertsOpen {
+		before = append(before, m.alerts.renderAlertPane(w, 8, m.theme))
+	}
+
+	// --- Replay timeline ---
+	if pl, ok := playerOf(m.source); ok {
+		after = append(after, renderTimeline(pl.Playback(), w, m.theme))
 	}
 
 	// --- Status bar ---
-	sections = append(sections, m.renderStatusBar(w))
+	after = append(after, m.renderStatusBar(w))
 
 	// --- Help ---
-	helpStyle := lipgloss.NewStyle().Foreground(colorDim).Width(w).Align(lipgloss.Center)
-	sections = append(sections, helpStyle.Render(m.help.View(keys)))
+	helpStyle := lipgloss.NewStyle().Foreground(m.theme.Dim).Width(w).Align(lipgloss.Center)
+	after = append(after, helpStyle.Render(m.help.View(keys)))
+
+	if m.height == 0 {
+		rows = len(m.tableRows())
+	} else {
+		used := 1 // table header
+		for _, s := range slices.Concat(top, before, after) {
+			used += lipgloss.Height(s)
+		}
+		rows = m.height - used
+	}
 
-	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+	// --- Detail pane (dropped when not even one line of it fits) ---
+	if _, ok := m.selected(); m.detailOpen && ok && (m.height == 0 || rows-minTableRows >= 4) {
+		detail := m.renderDetail(w, 0)
+		if m.height > 0 {
+			if room := rows - minTableRows; lipgloss.Height(detail) > room {
+				detail = m.renderDetail(w, room)
+			}
+			rows -= lipgloss.Height(detail)
+		}
+		before = append(before, detail)
+	}
+	return append(before, after...), max(rows, minTableRows)
+}
+
+// scrollOffset is the first table row to show: offset moved just enough to
+// keep the cursor on screen.
+func scrollOffset(offset, cursor, height, n int) int {
+	if cursor < offset {
+		offset = cursor
+	}
+	if cursor >= offset+height {
+		offset = cursor - height + 1
+	}
+	return clamp(offset, 0, max(n-height, 0))
+}
+
+// scrollToCursor stores the scroll position the next frame will draw.
+func (m *model) scrollToCursor() {
+	m.offset = scrollOffset(m.offset, m.cursor, m.tableHeight(), len(m.tableRows()))
+}
+
+// tableHeight is how many agent rows the table shows at the current size.
+func (m model) tableHeight() int {
+	w := m.width
+	if w == 0 {
+		w = 140
+	}
+	_, rows := m.layout(w, m.topSections(w))
+	return rows
 }
 
-// renderTable draws the main agent table with phase, progress, tok/s columns.
-func (m model) renderTable(w int) string {
-	// Column widths: ID(11) Name(16) Status(9) Phase(9) Progress(16) Tok/s(8) Uptime(8) Process(rest)
-	cID, cName, cStatus, cPhase, cProg, cTok, cUp := 11, 16, 9, 9, 16, 8, 8
-	cProc := w - cID - cName - cStatus - cPhase - cProg - cTok - cUp - 10
+// renderTable draws the main agent table with phase, progress, tok/s columns,
+// scrolled so that at most height rows show and the cursor is one of them.
+func (m model) renderTable(w, height int) string {
+	// Column widths from the config; Process takes the rest
+	cols := m.config.Columns
+	cID, cName, cStatus, cPhase, cProg, cTok, cTrend, cCost, cUp :=
+		cols.ID, cols.Name, cols.Status, cols.Phase, cols.Progress, cols.Tok, cols.Trend, cols.Cost, cols.Uptime
+	cProc := w - cols.sum() - 12
 	if cProc < 15 {
 		cProc = 15
 	}
 
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(colorFg).Underline(true)
-	header := fmt.Sprintf(" %-*s %-*s %-*s %-*s %-*s %-*s %-*s %-*s",
-		cID, "AGENT ID", cName, "NAME", cStatus, "STATUS",
-		cPhase, "PHASE", cProg, "PROGRESS", cTok, "TOK/S",
-		cUp, "UPTIME", cProc, "CURRENT PROCESS")
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(m.theme.Fg).Underline(true)
+	header := fmt.Sprintf(" %-*s %-*s %-*s %-*s %-*s %-*s %-*s %-*s %-*s %-*s",
+		cID, m.headerLabel("AGENT ID", sortID), cName, m.headerLabel("NAME", sortName),
+		cStatus, m.headerLabel("STATUS", sortStatus), cPhase, m.headerLabel("PHASE", sortPhase),
+		cProg, m.headerLabel("PROGRESS", sortProgress), cTok, m.headerLabel("TOK/S", sortTokens),
+		cTrend, "TREND", cCost, "COST", cUp, m.headerLabel("UPTIME", sortUptime), cProc, "CURRENT PROCESS")
 
 	rows := []string{headerStyle.Render(header)}
+	table := m.tableRows()
+	if len(table) == 0 {
+		rows = append(rows, lipgloss.NewStyle().Foreground(m.theme.Dim).
+			Render(" No agents match the filter. Press esc to clear it."))
+	}
 
-	for i, a := range m.agents {
+	off := scrollOffset(m.offset, m.cursor, height, len(table))
+	for i, r := range table {
+		if i < off || i >= off+height {
+			continue
+		}
+		a := r.Agent
 		// Status (colored)
-		stStyle := lipgloss.NewStyle().Foreground(a.Status.Color())
+		stStyle := lipgloss.NewStyle().Foreground(m.theme.status(a.Status))
 		stStr := stStyle.Render(fmt.Sprintf("%-*s", cStatus, a.Status.String()))
 
 		// Phase (colored with icon)
-		phStr := lipgloss.NewStyle().Foreground(colorDim).Render(fmt.Sprintf("%-*s", cPhase, "--"))
+		phStr := lipgloss.NewStyle().Foreground(m.theme.Dim).Render(fmt.Sprintf("%-*s", cPhase, "--"))
 		if a.Status == StatusRunning && a.Phase < PhaseDone {
-			phStyle := lipgloss.NewStyle().Foreground(colorAccent).Bold(true)
+			phStyle := lipgloss.NewStyle().Foreground(m.theme.Accent).Bold(true)
 			phStr = phStyle.Render(fmt.Sprintf("%-*s", cPhase, a.Phase.Icon()+" "+a.Phase.String()[:3]))
 		} else if a.Phase == PhaseDone {
-			phStr = lipgloss.NewStyle().Foreground(colorIdle).Render(fmt.Sprintf("%-*s", cPhase, "🏁 DONE"))
+			phStr = lipgloss.NewStyle().Foreground(m.theme.Idle).Render(fmt.Sprintf("%-*s", cPhase, "🏁 DONE"))
 		}
 
 		// Progress bar
-		progStr := renderProgressBar(a.Progress, cProg)
+		progStr := renderProgressBar(a.Progress, cProg, m.theme)
 		if a.Status == StatusStopped {
-			progStr = lipgloss.NewStyle().Foreground(colorDim).Render(fmt.Sprintf("%-*s", cProg, "   --"))
+			progStr = lipgloss.NewStyle().Foreground(m.theme.Dim).Render(fmt.Sprintf("%-*s", cProg, "   --"))
 		}
 
-		// Tok/s
-		tokStr := lipgloss.NewStyle().Foreground(colorDim).Render(fmt.Sprintf("%-*s", cTok, "--"))
-		if a.Status == StatusRunning && a.TokensPerSec > 0 {
-			tokColor := colorIdle // green for good throughput
+		// Tok/s (a parent in tree view sums its whole subtree)
+		tokStr := lipgloss.NewStyle().Foreground(m.theme.Dim).Render(fmt.Sprintf("%-*s", cTok, "--"))
+		if r.kids > 0 {
+			if rate := shownTokRate(a) + r.total.TokensPerSec; rate > 0 {
+				tokStr = lipgloss.NewStyle().Foreground(m.theme.Accent).
+					Render(fmt.Sprintf("%-*s", cTok, fmt.Sprintf("Σ%.0f", rate)))
+			}
+		} else if a.Status == StatusRunning && a.TokensPerSec > 0 {
+			tokColor := m.theme.Idle // green for good throughput
 			if a.TokensPerSec < 50 {
-				tokColor = colorRunning // yellow for slower
+				tokColor = m.theme.Running // yellow for slower
 			}
 			tokStr = lipgloss.NewStyle().Foreground(tokColor).
 				Render(fmt.Sprintf("%-*s", cTok, fmt.Sprintf("%.0f", a.TokensPerSec)))
 		}
 
+		// Trend: tok/s sparkline over the recent history
+		trendStr := strings.Repeat(" ", cTrend)
+		if h, ok := m.history[a.ID]; ok {
+			trendStr = lipgloss.NewStyle().Foreground(m.theme.Bar).Render(sparkline(h.tokRate.values(), cTrend, 0))
+		}
+
+		// Cost (red when over budget, like the rest of the row's identity;
+		// a parent in tree view includes its subtree)
+		name := a.Name
+		if m.treeView {
+			name = truncate(r.treeName(m.collapsed[a.ID]), cName)
+		}
+		// A warning badge marks agents with alerts firing
+		id := a.ID
+		if len(m.alerts.active[a.ID]) > 0 {
+			id = "⚠ " + a.ID
+		}
+		idStr, nameStr := fmt.Sprintf("%-*s", cID, id), fmt.Sprintf("%-*s", cName, name)
+		costStr := fmt.Sprintf("%-*s", cCost, fmtCost(m.pricing.Cost(a)+r.total.Cost))
+		if len(m.alerts.active[a.ID]) > 0 {
+			idStr = lipgloss.NewStyle().Foreground(m.theme.Running).Bold(true).Render(idStr)
+		}
+		if m.pricing.OverBudget(a) {
+			over := lipgloss.NewStyle().Foreground(m.theme.Error).Bold(true)
+			idStr, nameStr, costStr = over.Render(idStr), over.Render(nameStr), over.Render(costStr)
+		}
+
 		// Uptime
 		upStr := "--"
 		if a.Status != StatusStopped {
 			upStr = fmtDuration(time.Since(a.StartedAt))
 		}
 
-		// Current process (tool + activity)
-		procStr := lipgloss.NewStyle().Foreground(colorDim).Render("--")
+		// Current process (tool + activity). A parent in tree view leads
+		// with its subtree's tokens and tool calls.
+		pw, sumStr := cProc, ""
+		if r.kids > 0 {
+			sumStr = fmt.Sprintf("Σ%s tok/%d tools", fmtTokens(a.TotalTokensIn+a.TotalTokensOut+r.total.TokensIn+r.total.TokensOut),
+				a.ToolsUsed+r.total.Tools)
+			pw = max(cProc-lipgloss.Width(sumStr)-3, 8)
+			sumStr = lipgloss.NewStyle().Foreground(m.theme.Accent).Render(sumStr)
+		}
+		noProc := lipgloss.NewStyle().Foreground(m.theme.Dim).Render("--")
+		procStr := noProc
 		if a.Status == StatusRunning {
 			proc := fmt.Sprintf("%s → %s", a.CurrentTool, a.LastActivity)
-			if len(proc) > cProc-1 {
-				proc = proc[:cProc-2] + "…"
+			if len(proc) > pw-1 {
+				proc = proc[:pw-2] + "…"
 			}
 			procStr = proc
+		} else if a.waiting() {
+			procStr = lipgloss.NewStyle().Foreground(m.theme.Paused).
+				Render(truncate("⏳ "+oneLine(a.Question.Text), pw-1))
 		} else if a.Status == StatusPaused {
-			procStr = lipgloss.NewStyle().Foreground(colorPaused).Render("⏳ Awaiting input")
+			procStr = lipgloss.NewStyle().Foreground(m.theme.Paused).Render("⏸ Paused")
 		} else if a.Status == StatusError {
-			procStr = lipgloss.NewStyle().Foreground(colorError).Render("✗ Error — see detail")
+			errText := "✗ Error — see detail"
+			if e, ok := a.lastError(); ok {
+				errText = "✗ " + e.summary()
+			} else if a.PID != 0 {
+				errText = fmt.Sprintf("✗ Exit %d — see detail", a.ExitCode)
+			}
+			errColor := m.theme.Error
+			if cd, ok := m.restarts.countdown(a.ID, time.Now()); ok {
+				errText, errColor = cd+" · "+strings.TrimPrefix(errText, "✗ "), m.theme.Running
+			} else if why, ok := m.restarts.haltedBy(a.ID); ok {
+				errText = "✗ " + why + " · " + strings.TrimPrefix(errText, "✗ ")
+			}
+			procStr = lipgloss.NewStyle().Foreground(errColor).Render(truncate(errText, pw-1))
+		} else if cd, ok := m.restarts.countdown(a.ID, time.Now()); ok {
+			procStr = lipgloss.NewStyle().Foreground(m.theme.Accent).Render(cd)
+		}
+
+		if sumStr != "" {
+			if procStr == noProc {
+				procStr = sumStr
+			} else {
+				procStr = sumStr + " · " + procStr
+			}
 		}
 
-		line := fmt.Sprintf(" %-*s %-*s %s %s %s %s %-*s %s",
-			cID, a.ID, cName, a.Name, stStr, phStr, progStr, tokStr,
+		line := fmt.Sprintf(" %s %s %s %s %s %s %s %s %-*s %s",
+			idStr, nameStr, stStr, phStr, progStr, tokStr, trendStr, costStr,
 			cUp, upStr, procStr)
 
+		line = lipgloss.NewStyle().MaxWidth(w).Render(line) // never wrap: rows are counted
 		if i == m.cursor {
-			rowStyle := lipgloss.NewStyle().Background(colorSelBg).Width(w)
-			line = rowStyle.Render(line)
+			if m.theme.Mono {
+				line = "▸" + line[1:] // reverse video stops at the first styled cell
+			}
+			line = m.theme.selected().Width(w).Render(line)
 		}
 		rows = append(rows, line)
 	}
@@ -720,19 +1225,19 @@ func (m model) renderTable(w int) string {
 }
 
 // renderDetail shows comprehensive agent information.
-func (m model) renderDetail(w int) string {
-	a := m.agents[m.cursor]
+func (m model) renderDetail(w, maxLines int) string {
+	a, _ := m.selected()
 
 	border := lipgloss.NewStyle().
 		BorderStyle(lipgloss.RoundedBorder()).
-		BorderForeground(colorBorder).
+		BorderForeground(m.theme.Border).
 		Padding(0, 1).Width(w - 4)
 
-	title := lipgloss.NewStyle().Bold(true).Foreground(colorTitle)
-	label := lipgloss.NewStyle().Bold(true).Foreground(colorFg)
-	dim := lipgloss.NewStyle().Foreground(colorDim)
-	pass := lipgloss.NewStyle().Foreground(colorIdle)
-	fail := lipgloss.NewStyle().Foreground(colorError)
+	title := lipgloss.NewStyle().Bold(true).Foreground(m.theme.Title)
+	label := lipgloss.NewStyle().Bold(true).Foreground(m.theme.Fg)
+	dim := lipgloss.NewStyle().Foreground(m.theme.Dim)
+	pass := lipgloss.NewStyle().Foreground(m.theme.Idle)
+	fail := lipgloss.NewStyle().Foreground(m.theme.Error)
 
 	var b strings.Builder
 
@@ -744,7 +1249,7 @@ func (m model) renderDetail(w int) string {
 	if a.Status != StatusStopped {
 		uptime = fmtDuration(time.Since(a.StartedAt))
 	}
-	stColored := lipgloss.NewStyle().Foreground(a.Status.Color()).Render(a.Status.String())
+	stColored := lipgloss.NewStyle().Foreground(m.theme.status(a.Status)).Render(a.Status.String())
 
 	col1 := fmt.Sprintf("%s %s\n%s %s\n%s %s\n%s %s",
 		label.Render("Type:"), a.Name,
@@ -756,7 +1261,17 @@ func (m model) renderDetail(w int) string {
 		label.Render("Uptime:"), uptime,
 		label.Render("Task:"), a.TaskDesc,
 		label.Render("Tools used:"), a.ToolsUsed,
-		label.Render("Progress:"), renderProgressBar(a.Progress, 20))
+		label.Render("Progress:"), renderProgressBar(a.Progress, 20, m.theme))
+	if a.ParentID != "" {
+		col1 += fmt.Sprintf("\n%s %s", label.Render("Parent:"), a.ParentID)
+	}
+	if a.PID != 0 {
+		proc := fmt.Sprintf("%d", a.PID)
+		if a.Status == StatusError || a.Status == StatusStopped || a.Status == StatusIdle {
+			proc += fmt.Sprintf(" (exit %d)", a.ExitCode)
+		}
+		col1 += fmt.Sprintf("\n%s %s", label.Render("PID:"), proc)
+	}
 
 	// Side by side
 	halfW := (w - 8) / 2
@@ -765,6 +1280,62 @@ func (m model) renderDetail(w int) string {
 	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, c1Style.Render(col1), c2Style.Render(col2)))
 	b.WriteString("\n")
 
+	// ── Alerts ──
+	if firing := m.alerts.alertsFor(a.ID); len(firing) > 0 {
+		warn := lipgloss.NewStyle().Foreground(m.theme.Running)
+		b.WriteString(title.Render("Alerts") + "\n")
+		for _, al := range firing {
+			b.WriteString(warn.Render(fmt.Sprintf("  ⚠ %s: %s", al.Kind, al.Message)) +
+				dim.Render("  since "+fmtAgo(al.Since)) + "\n")
+		}
+	}
+
+	// ── Question ──
+	if a.waiting() {
+		q := a.Question
+		b.WriteString(title.Render("Awaiting Input") + dim.Render(fmt.Sprintf("  asked %s  %s: answer",
+			fmtAgo(q.Asked), keys.Answer.Help().Key)) + "\n")
+		b.WriteString(lipgloss.NewStyle().Foreground(m.theme.Paused).Render("  ⏳ "+truncate(oneLine(q.Text), w-12)) + "\n")
+		for i, o := range q.Options {
+			b.WriteString(dim.Render(fmt.Sprintf("    %d. ", i+1)) + o + "\n")
+		}
+	}
+
+	// ── Error ──
+	earlier := a.Errors
+	if last, ok := a.lastError(); ok && a.Status == StatusError {
+		earlier = a.Errors[:len(a.Errors)-1]
+		b.WriteString(title.Render("Error") + "\n")
+		b.WriteString(fail.Render("  ✗ "+truncate(last.Message, max(w-12, 8))) + "\n")
+		var meta []string
+		if last.Tool != "" {
+			meta = append(meta, label.Render("Tool:")+" "+last.Tool)
+		}
+		if last.ExitCode != 0 {
+			meta = append(meta, fmt.Sprintf("%s %d", label.Render("Exit code:"), last.ExitCode))
+		}
+		meta = append(meta,
+			fmt.Sprintf("%s %d", label.Render("Retries:"), last.Retries),
+			label.Render("At:")+" "+last.Time.Format("15:04:05")+dim.Render(" ("+fmtAgo(last.Time)+")"))
+		b.WriteString("    " + strings.Join(meta, "   ") + "\n")
+		if cd, ok := m.restarts.countdown(a.ID, time.Now()); ok {
+			b.WriteString("    " + label.Render("Restart:") + " " + cd + "\n")
+		} else if why, ok := m.restarts.haltedBy(a.ID); ok {
+			b.WriteString("    " + label.Render("Restart:") + " " + fail.Render(why) + "\n")
+		}
+		for _, l := range last.Stderr {
+			b.WriteString(dim.Render("    │ "+truncate(l, max(w-16, 8))) + "\n")
+		}
+	}
+	if len(earlier) > 0 {
+		b.WriteString(title.Render("Error History") + dim.Render(fmt.Sprintf("  %d earlier", len(earlier))) + "\n")
+		for i := len(earlier) - 1; i >= 0; i-- {
+			e := earlier[i]
+			b.WriteString(dim.Render("  "+e.Time.Format("15:04:05")+"  ") + truncate(e.summary(), max(w-32, 8)) +
+				dim.Render(fmt.Sprintf("  retry %d", e.Retries)) + "\n")
+		}
+	}
+
 	// ── Token Stats ──
 	// TODO: Replace with real PAI API — read from agent session's token usage endpoint
 	b.WriteString(title.Render("Token Metrics") + "\n")
@@ -773,39 +1344,61 @@ func (m model) renderDetail(w int) string {
 		label.Render("Input:"), fmtTokens(a.TotalTokensIn),
 		label.Render("Output:"), fmtTokens(a.TotalTokensOut),
 		label.Render("Total:"), fmtTokens(a.TotalTokensIn+a.TotalTokensOut)))
-
-	// ── Phase Timeline ──
-	b.WriteString(title.Render("Phase Timeline") + "\n  ")
-	for p := PhaseObserve; p <= PhaseLearn; p++ {
-		icon := p.Icon()
-		name := p.String()[:3]
-		if p < a.Phase {
-			b.WriteString(pass.Render(icon+" "+name) + " → ")
-		} else if p == a.Phase {
-			b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorAccent).Render("▶"+icon+" "+name) + " → ")
-		} else {
-			b.WriteString(dim.Render(icon+" "+name) + " → ")
+	costLine := fmt.Sprintf("  %s %s   %s %s/min   %s %s cached",
+		label.Render("Cost:"), fmtCost(m.pricing.Cost(a)),
+		label.Render("Rate:"), fmtCost(m.pricing.CostRate(a)),
+		label.Render("Cache:"), fmtTokens(a.TotalTokensCached))
+	if b := m.pricing.Budget(a); b > 0 {
+		budget := fmt.Sprintf("   %s %s", label.Render("Budget:"), fmtCost(b))
+		if m.pricing.OverBudget(a) {
+			budget += fail.Render("  OVER BUDGET")
 		}
+		costLine += budget
 	}
-	b.WriteString("\n")
+	b.WriteString(costLine + "\n")
+	if sub := m.selectedSubtree(); sub.Agents > 0 {
+		b.WriteString(fmt.Sprintf("  %s %d   %s %s in   %s %s out   %s %d   %s %s\n",
+			label.Render("Sub-agents:"), sub.Agents,
+			label.Render("Input:"), fmtTokens(sub.TokensIn),
+			label.Render("Output:"), fmtTokens(sub.TokensOut),
+			label.Render("Tools:"), sub.Tools,
+			label.Render("Cost:"), fmtCost(sub.Cost)))
+	}
+
+	// ── History ──
+	if h, ok := m.history[a.ID]; ok && h.tokRate.n > 0 {
+		b.WriteString(renderHistory(h, w, m.theme))
+	}
+
+	// ── Phase Timeline ──
+	b.WriteString(m.renderPhaseTimeline(a, w))
 
 	// ── ISC Criteria ──
-	// TODO: Replace with real ISC.json from agent's WORK directory
-	b.WriteString(title.Render("ISC Criteria") + "\n")
+	iscTitle := title.Render("ISC Criteria")
+	if a.WorkDir != "" {
+		iscTitle += dim.Render("  " + filepath.Join(a.WorkDir, iscFileName))
+	}
+	b.WriteString(iscTitle + "\n")
 	passed, total := 0, len(a.ISCItems)
 	for _, c := range a.ISCItems {
+		mark := fail.Render("  ✗ ")
 		if c.Passed {
 			passed++
-			b.WriteString(pass.Render("  ✓ ") + c.Text + "\n")
-		} else {
-			b.WriteString(fail.Render("  ✗ ") + c.Text + "\n")
+			mark = pass.Render("  ✓ ")
+		}
+		line := mark + c.Text
+		if !c.VerifiedAt.IsZero() {
+			line += dim.Render("  verified " + fmtAgo(c.VerifiedAt))
+		}
+		b.WriteString(line + "\n")
+		if c.Evidence != "" {
+			b.WriteString(dim.Render("      ↳ "+truncate(oneLine(c.Evidence), max(w-16, 8))) + "\n")
 		}
 	}
 	b.WriteString(dim.Render(fmt.Sprintf("  [%d/%d passed]\n", passed, total)))
 
 	// ── Recent Events ──
-	// TODO: Replace with real JSONL event stream from ~/.claude/history/raw-outputs/
-	b.WriteString(title.Render("Recent Events") + "\n")
+	b.WriteString(title.Render("Recent Events") + dim.Render("  "+keys.Log.Help().Key+": full log") + "\n")
 	start := len(a.EventLog) - 8
 	if start < 0 {
 		start = 0
@@ -814,7 +1407,13 @@ func (m model) renderDetail(w int) string {
 		b.WriteString(dim.Render("  ") + entry + "\n")
 	}
 
-	return border.Render(b.String())
+	// Cut to fit, leaving room for the border
+	body := b.String()
+	if lines := strings.Split(body, "\n"); maxLines > 0 && len(lines) > maxLines-2 {
+		keep := max(maxLines-3, 0)
+		body = strings.Join(lines[:keep], "\n") + "\n" + dim.Render(fmt.Sprintf("  … %d more lines", len(lines)-keep))
+	}
+	return border.Render(body)
 }
 
 func fmtTokens(n int) string {
@@ -827,24 +1426,102 @@ func fmtTokens(n int) string {
 	return fmt.Sprintf("%d", n)
 }
 
-// renderStatusBar shows aggregate metrics.
+// renderTimeline draws the replay scrubber: state, speed, position bar and clock.
+func renderTimeline(st PlaybackState, w int, th Theme) string {
+	icon := "⏵"
+	if st.Paused {
+		icon = "⏸"
+	}
+	left := lipgloss.NewStyle().Bold(true).Foreground(th.Accent).
+		Render(fmt.Sprintf(" %s REPLAY %4gx ", icon, st.Speed))
+	right := lipgloss.NewStyle().Foreground(th.Fg).
+		Render(fmt.Sprintf(" %s / %s", fmtDuration(st.Position), fmtDuration(st.Length)))
+
+	barW := w - lipgloss.Width(left) - lipgloss.Width(right) - 2
+	if barW < 10 {
+		barW = 10
+	}
+	head := 0
+	if st.Length > 0 {
+		head = int(float64(barW-1) * float64(st.Position) / float64(st.Length))
+	}
+	bar := lipgloss.NewStyle().Foreground(th.Bar).Render(strings.Repeat("━", head)) +
+		lipgloss.NewStyle().Foreground(th.Accent).Render("●") +
+		lipgloss.NewStyle().Foreground(th.Dim).Render(strings.Repeat("─", barW-1-head))
+	return left + bar + right
+}
+
+// fleetSummary is the aggregate shown in the status bar and exported by
+// --json/--watch.
+type fleetSummary struct {
+	Total        int
+	Counts       map[AgentStatus]int
+	TokensPerSec float64
+	Cost         float64 // USD, filled in by callers that have a Pricing
+	CostRate     float64 // USD per minute
+	OverBudget   int
+}
+
+func summarize(agents []Agent) fleetSummary {
+	sum := fleetSummary{Total: len(agents), Counts: map[AgentStatus]int{}}
+	for _, a := range agents {
+		sum.Counts[a.Status]++
+		sum.TokensPerSec += a.TokensPerSec
+	}
+	return sum
+}
+
+// renderStatusBar shows aggregate metrics for the rows on screen.
 func (m model) renderStatusBar(w int) string {
-	counts := map[AgentStatus]int{}
-	var totalTok float64
-	for _, a := range m.agents {
-		counts[a.Status]++
-		totalTok += a.TokensPerSec
+	shown := m.filtered()
+	sum := summarize(shown)
+	alerts := 0
+	for _, a := range shown {
+		sum.Cost += m.pricing.Cost(a)
+		sum.CostRate += m.pricing.CostRate(a)
+		if m.pricing.OverBudget(a) {
+			sum.OverBudget++
+		}
+		alerts += len(m.alerts.active[a.ID])
 	}
 
+	total := fmt.Sprintf("Agents: %d", sum.Total)
+	if m.filter.active() {
+		total = fmt.Sprintf("Agents: %d/%d", sum.Total, len(m.agents))
+	}
 	parts := []string{
-		fmt.Sprintf("Agents: %d", len(m.agents)),
-		lipgloss.NewStyle().Foreground(colorRunning).Render(fmt.Sprintf("⚡%d running", counts[StatusRunning])),
-		lipgloss.NewStyle().Foreground(colorIdle).Render(fmt.Sprintf("✓%d idle", counts[StatusIdle])),
-		lipgloss.NewStyle().Foreground(colorError).Render(fmt.Sprintf("✗%d err", counts[StatusError])),
-		fmt.Sprintf("Σ %.0f tok/s", totalTok),
+		total,
+		lipgloss.NewStyle().Foreground(m.theme.Running).Render(fmt.Sprintf("⚡%d running", sum.Counts[StatusRunning])),
+		lipgloss.NewStyle().Foreground(m.theme.Idle).Render(fmt.Sprintf("✓%d idle", sum.Counts[StatusIdle])),
+		lipgloss.NewStyle().Foreground(m.theme.Error).Render(fmt.Sprintf("✗%d err", sum.Counts[StatusError])),
+		fmt.Sprintf("Σ %.0f tok/s", sum.TokensPerSec),
+		fmt.Sprintf("%s (%s/min)", fmtCost(sum.Cost), fmtCost(sum.CostRate)),
+	}
+	if n := len(waitingAgents(shown)); n > 0 {
+		parts = append(parts, lipgloss.NewStyle().Foreground(m.theme.Paused).Bold(true).
+			Render(fmt.Sprintf("⏳%d waiting", n)))
+	}
+	if alerts > 0 {
+		parts = append(parts, lipgloss.NewStyle().Foreground(m.theme.Running).Bold(true).
+			Render(fmt.Sprintf("⚠%d alerts", alerts)))
+	}
+	if sum.OverBudget > 0 {
+		parts = append(parts, lipgloss.NewStyle().Foreground(m.theme.Error).
+			Render(fmt.Sprintf("$!%d over budget", sum.OverBudget)))
 	}
 	left := strings.Join(parts, "  │  ")
-	right := lipgloss.NewStyle().Foreground(colorDim).Render("⟳ " + m.lastRefresh.Format("15:04:05"))
+	right := lipgloss.NewStyle().Foreground(m.theme.Dim).Render("⟳ " + m.lastRefresh.Format("15:04:05"))
+	if m.count > 0 {
+		right = lipgloss.NewStyle().Bold(true).Foreground(m.theme.Accent).Render(strconv.Itoa(m.count)) + "  " + right
+	}
+	if m.confirm != nil {
+		right = lipgloss.NewStyle().Bold(true).Foreground(m.theme.Running).Render(m.confirm.prompt) + "  " + right
+	} else if m.notice != "" {
+		room := w - lipgloss.Width(left) - lipgloss.Width(right) - 8
+		if room > 8 {
+			right = lipgloss.NewStyle().Foreground(m.theme.Error).Render(truncate(m.notice, room)) + "  " + right
+		}
+	}
 
 	gap := w - lipgloss.Width(left) - lipgloss.Width(right) - 4
 	if gap < 1 {
@@ -853,7 +1530,7 @@ func (m model) renderStatusBar(w int) string {
 
 	barStyle := lipgloss.NewStyle().
 		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(colorBorder).
+		BorderForeground(m.theme.Border).
 		BorderTop(true).
 		Width(w - 2).Padding(0, 1)
 
@@ -865,12 +1542,30 @@ func (m model) renderStatusBar(w int) string {
 // ---------------------------------------------------------------------------
 
 func main() {
+	screenshot := flag.Bool("screenshot", false, "render one frame to stdout and exit")
+	jsonOut := flag.Bool("json", false, "print one JSON snapshot of all agents and exit")
+	watch := flag.Bool("watch", false, "stream one NDJSON snapshot per update instead of running the TUI")
+	sourceName := flag.String("source", "sim", "agent source: "+strings.Join(sourceNames, ", "))
+	historyDir := flag.String("history-dir", defaultHistoryDir(), "directory of raw-outputs JSONL files for --source live")
+	workRoot := flag.String("work-dir", defaultWorkRoot(), "root of per-session WORK directories holding ISC.json")
+	agentCmd := flag.String("agent-cmd", "", "shell command to spawn when starting an agent (PAI_AGENT_* set in its env)")
+	recordPath := flag.String("record", "", "write every agent state change to FILE for later --replay")
+	replayPath := flag.String("replay", "", "play back a --record FILE instead of reading a live source")
+	metricsAddr := flag.String("metrics-addr", "", "serve Prometheus metrics at http://ADDR/metrics (e.g. :9464)")
+	pricesPath := flag.String("prices", "", "JSON file with per-model token prices and per-agent budgets (instead of [prices])")
+	alertRulesPath := flag.String("alert-rules", "", "JSON file with stall/anomaly alert thresholds (instead of [alerts])")
+	notifyPath := flag.String("notify", "", "JSON file with bell/desktop/webhook notification rules (instead of [[notify.rules]])")
+	eventLogDir := flag.String("event-log-dir", "", "keep every agent's full event log in DIR/<agent id>.log")
+	stopGrace := flag.Duration("stop-grace", defaultStopGrace, "wait between SIGTERM and SIGKILL when stopping an agent")
+	configPath := flag.String("config", defaultConfigPath(), "TOML config file")
+	flag.Parse()
+
 	rand.Seed(time.Now().UnixNano())
 
 	// --screenshot flag: render one frame to stdout and exit (for captures)
-	if len(os.Args) > 1 && os.Args[1] == "--screenshot" {
+	if *screenshot {
 		rand.Seed(42) // fixed seed for consistent output
-		m := initialModel()
+		m := newModel(newSimulatedSource(10))
 		m.loading = false
 		m.width = 160
 		m.height = 50
@@ -881,17 +1576,168 @@ func main() {
 			a = &m.agents[1]; a.Name = "ClaudeResearcher"; a.Status = StatusRunning; a.Phase = PhaseExecute; a.Progress = 72; a.TokensPerSec = 135; a.CurrentTool = "WebSearch"; a.LastActivity = "WebSearch: Go TUI frameworks"; a.Model = "claude-sonnet-4-5"
 			a = &m.agents[2]; a.Name = "Architect"; a.Status = StatusIdle; a.Phase = PhaseDone; a.Progress = 100
 			a = &m.agents[3]; a.Name = "GeminiResearcher"; a.Status = StatusRunning; a.Phase = PhaseObserve; a.Progress = 12; a.TokensPerSec = 245; a.CurrentTool = "Read"; a.LastActivity = "Read src/auth/middleware.ts"; a.Model = "claude-haiku-4-5"
-			a = &m.agents[4]; a.Name = "QATester"; a.Status = StatusError; a.Progress = 45
+			a = &m.agents[4]; a.Name = "QATester"; a.Status = StatusError; a.Progress = 45; a.Retries = 1
+			a.Errors = []AgentError{errorPool[1], errorPool[0]}
+			a.Errors[0].Time, a.Errors[1].Time, a.Errors[1].Retries = time.Now().Add(-4*time.Minute), time.Now().Add(-40*time.Second), 1
 			a = &m.agents[5]; a.Name = "Pentester"; a.Status = StatusRunning; a.Phase = PhaseVerify; a.Progress = 88; a.TokensPerSec = 98; a.CurrentTool = "Bash"; a.LastActivity = "Bash: npm run test"; a.Model = "gemini-2.5-pro"
 			a = &m.agents[6]; a.Name = "Designer"; a.Status = StatusPaused; a.Phase = PhasePlan; a.Progress = 35
+			a.Question = questionPool[0]; a.Question.Asked = time.Now().Add(-70 * time.Second)
 			a = &m.agents[7]; a.Name = "Algorithm"; a.Status = StatusRunning; a.Phase = PhaseThink; a.Progress = 28; a.TokensPerSec = 112; a.CurrentTool = "Task"; a.LastActivity = "Task: spawned Intern agent"; a.Model = "claude-sonnet-4-5"
 		}
+		m.agents[5].LastActTime = time.Now().Add(-95 * time.Second) // stalled, to show an alert
+		// Backfill a few minutes of history so the sparklines have a shape
+		for _, a := range m.agents {
+			h := &agentHistory{lastTools: a.ToolsUsed}
+			for i := 0; i < 40; i++ {
+				ramp := 0.6 + 0.4*float64(i)/40 + (rand.Float64()-0.5)*0.3
+				h.tokRate.push(shownTokRate(a) * ramp)
+				h.progress.push(float64(a.Progress * (i + 1) / 40))
+				h.toolCalls.push(float64(rand.Intn(3)))
+			}
+			m.history[a.ID] = h
+		}
+		// Backfill phase timings for the selected agent and averages for its type
+		at := time.Now().Add(-303 * time.Second)
+		var spans []PhaseSpan
+		for i, d := range []time.Duration{38 * time.Second, 134 * time.Second, 51 * time.Second, 80 * time.Second} {
+			span := PhaseSpan{Phase: Phase(i), Start: at, Tokens: int(d.Seconds()) * 170}
+			if at = at.Add(d); i < 3 {
+				span.End = at
+			}
+			spans = append(spans, span)
+		}
+		m.agents[0].Phases = spans
+		m.phases.avg["Engineer"] = &[PhaseDone]phaseAvg{{6, 4 * time.Minute}, {6, 9 * time.Minute}, {6, 5 * time.Minute},
+			{5, 11 * time.Minute}, {5, 6 * time.Minute}, {5, 4 * time.Minute}, {5, 2 * time.Minute}}
+		m.alerts.evaluate(m.agents, m.history, time.Now())
 		fmt.Println(m.View())
 		return
 	}
 
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	// Config file: [source] fills in flags not given on the command line
+	explicit := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	cfg, err := loadConfig(*configPath, explicit["config"])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	cfg.sourceFlags(func(name, value string) {
+		if !explicit[name] {
+			flag.Set(name, value)
+		}
+	})
+	var configInfo os.FileInfo
+	if *configPath != "" {
+		configInfo, _ = os.Stat(*configPath)
+	}
+
+	var pricesFlag *Pricing
+	if *pricesPath != "" {
+		p, err := loadPricing(*pricesPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		pricesFlag = &p
+	}
+	var alertsFlag *AlertRules
+	if *alertRulesPath != "" {
+		r, err := loadAlertRules(*alertRulesPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		alertsFlag = &r
+	}
+	var notifyFlag *notifyConfig
+	if *notifyPath != "" {
+		c, err := loadNotifyRules(*notifyPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		notifyFlag = &c
+	}
+
+	if *replayPath != "" {
+		*sourceName = "replay"
+	}
+	src, err := newSource(*sourceName, sourceOptions{historyDir: *historyDir, workRoot: *workRoot, replayPath: *replayPath})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	if *agentCmd != "" {
+		src = newProcSource(src, *agentCmd, *stopGrace)
+	}
+	var recorder *recordSource
+	if *recordPath != "" {
+		if recorder, err = newRecordSource(src, *recordPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		src = recorder
+	}
+	src.Start()
+
+	if *metricsAddr != "" {
+		srv, err := serveMetrics(*metricsAddr, src)
+		if err != nil {
+			src.Stop()
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		defer srv.Close()
+	}
+
+	// --json / --watch: headless, machine-readable output
+	if *jsonOut || *watch {
+		write := writeJSON
+		if *watch {
+			write = watchJSON
+		}
+		err := write(os.Stdout, src)
+		src.Stop()
+		if err == nil {
+			err = recorder.takeErr()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var events *eventStore
+	if *eventLogDir != "" {
+		if events, err = newEventStore(expandHome(*eventLogDir)); err != nil {
+			src.Stop()
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	m := newModel(src)
+	// Ask the terminal now: lipgloss caches the answer, so adaptive colors
+	// never query it once Bubble Tea owns stdin.
+	m.darkBg = lipgloss.HasDarkBackground()
+	m.pricesFlag, m.alertsFlag, m.notifyFlag = pricesFlag, alertsFlag, notifyFlag
+	m.applyConfig(cfg)
+	m.configPath, m.configInfo = *configPath, configInfo
+	m.events = events
+	m.recorder = recorder
+	for _, a := range m.agents {
+		events.observe(Agent{}, a)
+	}
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	_, err = p.Run()
+	src.Stop()
+	events.Close()
+	if err == nil {
+		err = recorder.takeErr()
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,103 @@
+package main
+
+// Prometheus exporter — optional /metrics endpoint behind --metrics-addr.
+//
+// Metrics are rendered in the text exposition format straight from a source
+// Snapshot on every scrape, so they match the table without any extra
+// bookkeeping and work in every mode (TUI, --watch).
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// serveMetrics starts the exporter. The listener is opened before returning
+// so a bad address fails at startup rather than silently later.
+func serveMetrics(addr string, src AgentSource) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("metrics: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writeMetrics(w, src.Snapshot())
+	})
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go srv.Serve(ln)
+	return srv, nil
+}
+
+// writeMetrics renders per-agent and fleet metrics.
+func writeMetrics(w io.Writer, agents []Agent) {
+	agentLabels := func(a Agent) string {
+		return fmt.Sprintf(`id="%s",name="%s",model="%s"`,
+			escapeLabel(a.ID), escapeLabel(a.Name), escapeLabel(a.Model))
+	}
+	family := func(name, typ, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+	perAgent := func(name, typ, help string, value func(Agent) float64) {
+		family(name, typ, help)
+		for _, a := range agents {
+			fmt.Fprintf(w, "%s{%s} %g\n", name, agentLabels(a), value(a))
+		}
+	}
+
+	perAgent("pai_agent_tokens_per_second", "gauge", "Current output token throughput.",
+		func(a Agent) float64 { return a.TokensPerSec })
+	perAgent("pai_agent_input_tokens_total", "counter", "Cumulative input tokens.",
+		func(a Agent) float64 { return float64(a.TotalTokensIn) })
+	perAgent("pai_agent_output_tokens_total", "counter", "Cumulative output tokens.",
+		func(a Agent) float64 { return float64(a.TotalTokensOut) })
+	perAgent("pai_agent_tool_calls_total", "counter", "Cumulative tool invocations.",
+		func(a Agent) float64 { return float64(a.ToolsUsed) })
+	perAgent("pai_agent_progress_ratio", "gauge", "Task progress from 0 to 1.",
+		func(a Agent) float64 { return float64(a.Progress) / 100 })
+	perAgent("pai_agent_uptime_seconds", "gauge", "Seconds since the agent started (0 when stopped).",
+		func(a Agent) float64 {
+			if a.Status == StatusStopped {
+				return 0
+			}
+			return time.Since(a.StartedAt).Seconds()
+		})
+
+	// State sets: one series per possible value, 1 for the current one.
+	family("pai_agent_status", "gauge", "Agent status (1 for the current status).")
+	for _, a := range agents {
+		for st := StatusRunning; st <= StatusStopped; st++ {
+			fmt.Fprintf(w, "pai_agent_status{%s,status=\"%s\"} %d\n",
+				agentLabels(a), strings.ToLower(st.String()), boolInt(a.Status == st))
+		}
+	}
+	family("pai_agent_phase", "gauge", "PAI Algorithm phase (1 for the current phase).")
+	for _, a := range agents {
+		for p := PhaseObserve; p <= PhaseDone; p++ {
+			fmt.Fprintf(w, "pai_agent_phase{%s,phase=\"%s\"} %d\n",
+				agentLabels(a), strings.ToLower(p.String()), boolInt(a.Phase == p))
+		}
+	}
+
+	sum := summarize(agents)
+	family("pai_agents", "gauge", "Number of agents by status.")
+	for st := StatusRunning; st <= StatusStopped; st++ {
+		fmt.Fprintf(w, "pai_agents{status=\"%s\"} %d\n", strings.ToLower(st.String()), sum.Counts[st])
+	}
+	family("pai_fleet_tokens_per_second", "gauge", "Sum of token throughput across all agents.")
+	fmt.Fprintf(w, "pai_fleet_tokens_per_second %g\n", sum.TokensPerSec)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,107 @@
+package main
+
+// Mouse — click a row to select it, double-click to open the detail pane,
+// wheel through the table or the event log, and click a column header to
+// sort by it.
+//
+// Hit-testing follows the layout View draws: the table header sits right
+// under topSections, agent rows follow from the scroll offset, and
+// tableHeight says how many of them fit next to the detail and alert panes.
+// Clicks anywhere else are ignored.
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// doubleClick is the longest gap between two clicks on a row that opens
+// its detail pane.
+const doubleClick = 400 * time.Millisecond
+
+// pagerWheelLines is how far one wheel step scrolls the event log.
+const pagerWheelLines = 3
+
+// updateMouse handles mouse events.
+func (m model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.pager != nil {
+		p := m.pager
+		if msg.Action == tea.MouseActionPress {
+			switch msg.Button {
+			case tea.MouseButtonWheelUp:
+				p.scroll(-pagerWheelLines)
+			case tea.MouseButtonWheelDown:
+				p.scroll(pagerWheelLines)
+			}
+			p.follow = p.atBottom()
+		}
+		return m, nil
+	}
+	if m.loading || m.overview || m.spawn != nil || m.answering != nil || m.helpOpen || m.confirm != nil || msg.Action != tea.MouseActionPress {
+		return m, nil
+	}
+	m.count = 0
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.moveCursor(-1)
+	case tea.MouseButtonWheelDown:
+		m.moveCursor(1)
+	case tea.MouseButtonLeft:
+		m.click(msg.X, msg.Y)
+	}
+	m.scrollToCursor()
+	return m, nil
+}
+
+// click selects the row under (x, y), or sorts by the header under it.
+func (m *model) click(x, y int) {
+	w := m.width
+	if w == 0 {
+		w = 140
+	}
+	top := 0
+	for _, s := range m.topSections(w) {
+		top += lipgloss.Height(s)
+	}
+	n, height := len(m.tableRows()), m.tableHeight()
+	switch {
+	case y == top:
+		if k, ok := m.columnAt(x); ok {
+			m.sortColumn(k)
+		}
+	case y > top && y <= top+height:
+		i := scrollOffset(m.offset, m.cursor, height, n) + y - top - 1
+		if i >= n {
+			return
+		}
+		now := time.Now()
+		if i == m.cursor && now.Sub(m.lastClick) < doubleClick {
+			m.detailOpen = true
+			now = time.Time{} // a third click starts over
+		}
+		m.cursor, m.lastClick = i, now
+	}
+}
+
+// columnAt returns the sort key of the table header column at x, or false
+// for columns that do not sort.
+func (m model) columnAt(x int) (sortKey, bool) {
+	c := m.config.Columns
+	cols := []struct {
+		width int
+		key   sortKey
+	}{
+		{c.ID, sortID}, {c.Name, sortName}, {c.Status, sortStatus}, {c.Phase, sortPhase},
+		{c.Progress, sortProgress}, {c.Tok, sortTokens}, {c.Trend, sortNone}, {c.Cost, sortNone},
+		{c.Uptime, sortUptime},
+	}
+	left := 1 // the row's leading space
+	for _, col := range cols {
+		if x >= left && x < left+col.width {
+			return col.key, col.key != sortNone
+		}
+		left += col.width + 1
+	}
+	return sortNone, false
+}
@@ -0,0 +1,299 @@
+package main
+
+// Notifications — bell, desktop and webhook alerts on agent transitions.
+//
+// Rules come from the config file's [[notify.rules]] entries. Each rule
+// picks the events and agents it cares about and how to deliver them:
+//
+//	[[notify.rules]]
+//	events  = ["error", "paused"]
+//	bell    = true
+//	desktop = "osc9"
+//
+//	[[notify.rules]]
+//	events       = ["done", "isc_passed"]
+//	agents       = ["Engineer"]
+//	webhook      = "http://localhost:8080/hook"
+//	min_interval = "1m"
+//
+// --notify FILE reads the rules from JSON instead and wins over the config:
+//
+//	{"rules": [{"events": ["error", "paused"], "bell": true, "desktop": "osc9"}]}
+//
+// Events: error (entered Error), done (reached the DONE phase), paused
+// (asked a question and awaits the answer; a pause by hand does not count),
+// isc_passed (every ISC criterion passes). Agents match by name or ID; an
+// empty list matches everything. A rule fires at most once per min_interval
+// (default 30s) for the same agent and event.
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	notifySchema          = "pai-tui.notification"
+	notifySchemaVersion   = 1
+	defaultNotifyInterval = 30 * time.Second
+	webhookTimeout        = 5 * time.Second
+	termSeqHold           = 100 * time.Millisecond // long enough for the renderer to draw a frame
+)
+
+type notifyEvent string
+
+const (
+	eventError     notifyEvent = "error"
+	eventDone      notifyEvent = "done"
+	eventPaused    notifyEvent = "paused"
+	eventISCPassed notifyEvent = "isc_passed"
+)
+
+var notifyEvents = []notifyEvent{eventError, eventDone, eventPaused, eventISCPassed}
+
+// NotifyRule is one [[notify.rules]] entry, or one rule of the --notify file.
+type NotifyRule struct {
+	Events      []notifyEvent `toml:"events" json:"events"`
+	Agents      []string      `toml:"agents" json:"agents"`
+	Bell        bool          `toml:"bell" json:"bell"`
+	Desktop     string        `toml:"desktop" json:"desktop"` // "", "osc9" or "osc777"
+	Webhook     string        `toml:"webhook" json:"webhook"`
+	MinInterval *duration     `toml:"min_interval" json:"min_interval"`
+}
+
+func (r NotifyRule) matches(ev notifyEvent, a Agent) bool {
+	if len(r.Events) > 0 && !slices.Contains(r.Events, ev) {
+		return false
+	}
+	return len(r.Agents) == 0 || slices.Contains(r.Agents, a.Name) || slices.Contains(r.Agents, a.ID)
+}
+
+func (r NotifyRule) interval() time.Duration {
+	if r.MinInterval == nil {
+		return defaultNotifyInterval
+	}
+	return time.Duration(*r.MinInterval)
+}
+
+// notifyConfig is the config file's [notify] table and the --notify file.
+type notifyConfig struct {
+	Rules []NotifyRule `toml:"rules" json:"rules"`
+}
+
+// loadNotifyRules reads and validates a --notify file.
+func loadNotifyRules(path string) (notifyConfig, error) {
+	var c notifyConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c, fmt.Errorf("notify: %w", err)
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("notify: parse %s: %w", path, err)
+	}
+	if err := checkNotifyRules(c.Rules); err != nil {
+		return c, fmt.Errorf("notify: %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// checkNotifyRules validates the [[notify.rules]] entries.
+func checkNotifyRules(rules []NotifyRule) error {
+	for i, r := range rules {
+		for _, ev := range r.Events {
+			if !slices.Contains(notifyEvents, ev) {
+				return fmt.Errorf("notify rule %d: unknown event %q (want error, done, paused or isc_passed)", i+1, ev)
+			}
+		}
+		if r.Desktop != "" && r.Desktop != "osc9" && r.Desktop != "osc777" {
+			return fmt.Errorf("notify rule %d: desktop must be \"osc9\" or \"osc777\"", i+1)
+		}
+		if r.Webhook != "" {
+			u, err := url.Parse(r.Webhook)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("notify rule %d: webhook must be an http(s) URL", i+1)
+			}
+		}
+		if !r.Bell && r.Desktop == "" && r.Webhook == "" {
+			return fmt.Errorf("notify rule %d: set at least one of bell, desktop, webhook", i+1)
+		}
+		if r.interval() < 0 {
+			return fmt.Errorf("notify rule %d: min_interval must not be negative", i+1)
+		}
+	}
+	return nil
+}
+
+// notification is one event about to be delivered.
+type notification struct {
+	Event   notifyEvent
+	Agent   Agent
+	Message string
+	Time    time.Time
+}
+
+// notifier turns agent transitions into deliveries. It is owned by the
+// model; observe queues and flush hands the queue to Bubble Tea as commands.
+type notifier struct {
+	rules   []NotifyRule
+	last    map[string]time.Time // rule/agent/event → last delivery
+	pending []tea.Cmd
+	client  *http.Client
+}
+
+func newNotifier(rules []NotifyRule) *notifier {
+	return &notifier{
+		rules:  rules,
+		last:   map[string]time.Time{},
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// notifyErrMsg reports a failed delivery to the status bar.
+type notifyErrMsg struct{ err error }
+
+// termSeqMsg carries bell and OSC sequences to the model, which draws them
+// with the next frame. The renderer owns the terminal: anything written
+// beside it could land in the middle of a frame.
+type termSeqMsg string
+
+// termSeqDoneMsg clears sequences once a frame has carried them.
+type termSeqDoneMsg struct{ gen int }
+
+// observe compares two versions of an agent and queues any notifications.
+func (n *notifier) observe(prev, next Agent, now time.Time) {
+	if len(n.rules) == 0 {
+		return
+	}
+	label := fmt.Sprintf("%s (%s)", next.ID, next.Name)
+	if next.Status == StatusError && prev.Status != StatusError {
+		n.queue(notification{eventError, next, label + " entered Error", now})
+	}
+	if next.Phase == PhaseDone && prev.Phase != PhaseDone {
+		n.queue(notification{eventDone, next, label + " finished", now})
+	}
+	if next.waiting() && !prev.waiting() {
+		n.queue(notification{eventPaused, next, label + " is awaiting input", now})
+	}
+	if allPassed(next.ISCItems) && !allPassed(prev.ISCItems) {
+		n.queue(notification{eventISCPassed, next,
+			fmt.Sprintf("%s passed all %d ISC criteria", label, len(next.ISCItems)), now})
+	}
+}
+
+func allPassed(items []ISCCriterion) bool {
+	for _, c := range items {
+		if !c.Passed {
+			return false
+		}
+	}
+	return len(items) > 0
+}
+
+// queue applies every matching rule that is not rate limited.
+func (n *notifier) queue(nt notification) {
+	for i, r := range n.rules {
+		if !r.matches(nt.Event, nt.Agent) {
+			continue
+		}
+		key := fmt.Sprintf("%d/%s/%s", i, nt.Agent.ID, nt.Event)
+		if t, ok := n.last[key]; ok && nt.Time.Sub(t) < r.interval() {
+			continue
+		}
+		n.last[key] = nt.Time
+		n.pending = append(n.pending, n.deliver(r, nt)...)
+	}
+}
+
+// flush returns the queued deliveries as one command.
+func (n *notifier) flush() tea.Cmd {
+	cmds := n.pending
+	n.pending = nil
+	return tea.Batch(cmds...)
+}
+
+// deliver returns the commands that send one notification through every
+// channel the rule enables.
+func (n *notifier) deliver(r NotifyRule, nt notification) []tea.Cmd {
+	var cmds []tea.Cmd
+	if seq := termSeq(r, nt); seq != "" {
+		cmds = append(cmds, func() tea.Msg { return termSeqMsg(seq) })
+	}
+	if r.Webhook != "" {
+		cmds = append(cmds, func() tea.Msg {
+			if err := n.post(r.Webhook, nt); err != nil {
+				return notifyErrMsg{fmt.Errorf("webhook: %w", err)}
+			}
+			return nil
+		})
+	}
+	return cmds
+}
+
+// termSeq is the bell and desktop notification a rule asks for.
+func termSeq(r NotifyRule, nt notification) string {
+	var seq strings.Builder
+	if r.Bell {
+		seq.WriteString("\a")
+	}
+	switch r.Desktop {
+	case "osc9":
+		fmt.Fprintf(&seq, "\x1b]9;%s\x07", oscText(nt.Message))
+	case "osc777":
+		fmt.Fprintf(&seq, "\x1b]777;notify;PAI %s;%s\x07", nt.Event, oscText(nt.Message))
+	}
+	return seq.String()
+}
+
+// webhookPayload is the JSON body POSTed to webhooks.
+type webhookPayload struct {
+	Schema  string    `json:"schema"`
+	Version int       `json:"version"`
+	Event   string    `json:"event"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+	Agent   jsonAgent `json:"agent"`
+}
+
+func (n *notifier) post(endpoint string, nt notification) error {
+	body, err := json.Marshal(webhookPayload{
+		Schema:  notifySchema,
+		Version: notifySchemaVersion,
+		Event:   string(nt.Event),
+		Message: nt.Message,
+		Time:    nt.Time,
+		Agent:   toJSONAgent(nt.Agent, nt.Time),
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return errors.New(resp.Status)
+	}
+	return nil
+}
+
+// oscText strips characters that would end or corrupt an OSC sequence.
+func oscText(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == ';' {
+			return ' '
+		}
+		return r
+	}, s)
+}
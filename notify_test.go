@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// run executes the queued deliveries and returns their messages.
+func (n *notifier) run() []tea.Msg {
+	var msgs []tea.Msg
+	for _, cmd := range n.pending {
+		if msg := cmd(); msg != nil {
+			msgs = append(msgs, msg)
+		}
+	}
+	n.pending = nil
+	return msgs
+}
+
+func TestNotifyRuleMatches(t *testing.T) {
+	eng := Agent{ID: "pai-1a2b", Name: "Engineer"}
+	tests := []struct {
+		name string
+		rule NotifyRule
+		ev   notifyEvent
+		want bool
+	}{
+		{"empty rule matches everything", NotifyRule{}, eventDone, true},
+		{"event listed", NotifyRule{Events: []notifyEvent{eventError, eventPaused}}, eventPaused, true},
+		{"event not listed", NotifyRule{Events: []notifyEvent{eventError}}, eventDone, false},
+		{"agent by name", NotifyRule{Agents: []string{"Engineer"}}, eventError, true},
+		{"agent by ID", NotifyRule{Agents: []string{"pai-1a2b"}}, eventError, true},
+		{"other agent", NotifyRule{Agents: []string{"Intern"}}, eventError, false},
+		{"event and agent must both match", NotifyRule{Events: []notifyEvent{eventDone}, Agents: []string{"Engineer"}}, eventError, false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.matches(tt.ev, eng); got != tt.want {
+			t.Errorf("%s: matches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoadNotifyRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    int // rules loaded
+		wantErr string
+	}{
+		{"rules", `{"rules": [{"events": ["error"], "bell": true}, {"webhook": "http://localhost/x", "min_interval": "1m"}]}`, 2, ""},
+		{"no rules", `{}`, 0, ""},
+		{"bad json", `{"rules": [`, 0, "notify: parse"},
+		{"bad duration", `{"rules": [{"bell": true, "min_interval": 60}]}`, 0, "min_interval"},
+		{"checked like the config", `{"rules": [{"events": ["crash"], "bell": true}]}`, 0, `notify rule 1: unknown event "crash"`},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "notify.json")
+		if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		c, err := loadNotifyRules(path)
+		switch {
+		case tt.wantErr != "":
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error %v, want one containing %q", tt.name, err, tt.wantErr)
+			}
+		case err != nil:
+			t.Errorf("%s: %v", tt.name, err)
+		case len(c.Rules) != tt.want:
+			t.Errorf("%s: %d rules, want %d", tt.name, len(c.Rules), tt.want)
+		}
+	}
+}
+
+func TestNotifierTransitions(t *testing.T) {
+	base := Agent{ID: "pai-1a2b", Name: "Engineer", Status: StatusRunning, Phase: PhaseBuild,
+		ISCItems: []ISCCriterion{{Text: "a", Passed: true}, {Text: "b"}}}
+	tests := []struct {
+		name   string
+		change func(a *Agent)
+		want   string // message of the one notification, "" for none
+	}{
+		{"error", func(a *Agent) { a.Status = StatusError }, "pai-1a2b (Engineer) entered Error"},
+		{"done", func(a *Agent) { a.Phase = PhaseDone }, "pai-1a2b (Engineer) finished"},
+		{"question asked", func(a *Agent) { a.Status, a.Question.Text = StatusPaused, "Which database?" },
+			"pai-1a2b (Engineer) is awaiting input"},
+		{"paused by hand", func(a *Agent) { a.Status = StatusPaused }, ""},
+		{"isc passed", func(a *Agent) { a.ISCItems = []ISCCriterion{{Text: "a", Passed: true}, {Text: "b", Passed: true}} },
+			"pai-1a2b (Engineer) passed all 2 ISC criteria"},
+		{"no transition", func(a *Agent) { a.Progress = 50 }, ""},
+	}
+	for _, tt := range tests {
+		n := newNotifier([]NotifyRule{{Desktop: "osc9"}})
+		next := copyAgent(base)
+		tt.change(&next)
+		n.observe(base, next, time.Now())
+		msgs := n.run()
+		if tt.want == "" {
+			if len(msgs) != 0 {
+				t.Errorf("%s: got %d notifications, want none", tt.name, len(msgs))
+			}
+			continue
+		}
+		if len(msgs) != 1 {
+			t.Errorf("%s: got %d notifications, want 1", tt.name, len(msgs))
+			continue
+		}
+		if want := termSeqMsg("\x1b]9;" + tt.want + "\x07"); msgs[0] != want {
+			t.Errorf("%s: got %q, want %q", tt.name, msgs[0], want)
+		}
+	}
+}
+
+func TestNotifierRateLimit(t *testing.T) {
+	minute := duration(time.Minute)
+	n := newNotifier([]NotifyRule{{Bell: true, MinInterval: &minute}})
+	t0 := time.Now()
+	a := Agent{ID: "pai-1a2b", Name: "Engineer"}
+	b := Agent{ID: "pai-3c4d", Name: "Engineer"}
+
+	steps := []struct {
+		agent Agent
+		at    time.Duration
+		want  int
+	}{
+		{a, 0, 1},
+		{a, 30 * time.Second, 0}, // within min_interval
+		{b, 30 * time.Second, 1}, // limits are per agent
+		{a, 61 * time.Second, 1},
+	}
+	for i, st := range steps {
+		n.queue(notification{eventError, st.agent, "x", t0.Add(st.at)})
+		if got := len(n.run()); got != st.want {
+			t.Errorf("step %d: got %d deliveries, want %d", i, got, st.want)
+		}
+	}
+}
+
+func TestWebhookPayload(t *testing.T) {
+	var got webhookPayload
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	n := newNotifier([]NotifyRule{{Events: []notifyEvent{eventError}, Webhook: srv.URL}})
+	prev := Agent{ID: "pai-1a2b", Name: "Engineer", Model: "claude-opus-4-6", Status: StatusRunning}
+	next := prev
+	next.Status = StatusError
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	n.observe(prev, next, now)
+	if msgs := n.run(); len(msgs) != 0 {
+		t.Fatalf("delivery failed: %v", msgs)
+	}
+
+	if got.Schema != notifySchema || got.Version != notifySchemaVersion {
+		t.Errorf("schema = %q v%d", got.Schema, got.Version)
+	}
+	if got.Event != "error" || got.Message != "pai-1a2b (Engineer) entered Error" || !got.Time.Equal(now) {
+		t.Errorf("event = %q, message = %q, time = %v", got.Event, got.Message, got.Time)
+	}
+	if got.Agent.ID != "pai-1a2b" || got.Agent.Status != "error" || got.Agent.Model != "claude-opus-4-6" {
+		t.Errorf("agent = %+v", got.Agent)
+	}
+
+	// A non-2xx response is reported
+	status = http.StatusInternalServerError
+	n.observe(prev, next, now.Add(time.Hour))
+	msgs := n.run()
+	if len(msgs) != 1 {
+		t.Fatalf("got %d messages, want 1", len(msgs))
+	}
+	if e, ok := msgs[0].(notifyErrMsg); !ok || !strings.Contains(e.err.Error(), "500") {
+		t.Errorf("got %v, want a webhook error", msgs[0])
+	}
+}
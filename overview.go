@@ -0,0 +1,359 @@
+package main
+
+// Fleet overview — the screen behind Tab: the whole fleet in aggregate
+// rather than row by row.
+//
+// It covers every agent, whatever the table's filter: status and phase
+// breakdowns, total throughput over the last few minutes and the last hour,
+// tokens by model, ISC pass rate, the top agents by cost and by tokens, and
+// the average time per phase for each agent type.
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// overviewTopN is how many agents the top-by-cost and top-by-tokens lists show.
+const overviewTopN = 5
+
+// fleetHistory is the fleet's total throughput: a sample every tick for
+// the last historyLen ticks, and one average per minute for the last hour.
+type fleetHistory struct {
+	recent ring
+	hourly ring
+	minute time.Time // start of the minute being averaged
+	sum    float64
+	n      int
+}
+
+func (h *fleetHistory) push(v float64, now time.Time) {
+	h.recent.push(v)
+	if h.minute.IsZero() {
+		h.minute = now
+	}
+	h.sum += v
+	h.n++
+	if now.Sub(h.minute) >= time.Minute {
+		h.hourly.push(h.sum / float64(h.n))
+		h.minute, h.sum, h.n = now, 0, 0
+	}
+}
+
+// updateOverview handles keys while the overview is showing. Table keys do
+// nothing here.
+func (m model) updateOverview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, keys.Overview), msg.Type == tea.KeyEsc:
+		m.overview = false
+	case key.Matches(msg, keys.Help):
+		m.helpOpen = true
+	case key.Matches(msg, keys.Refresh):
+		m.source.Refresh()
+	}
+	return m, nil
+}
+
+// renderOverview draws the overview screen between the title and status bars.
+func (m model) renderOverview(w int) string {
+	top := m.topSections(w)
+	status := m.renderStatusBar(w)
+	hint := lipgloss.NewStyle().Foreground(m.theme.Dim).Width(w).Align(lipgloss.Center).
+		Render(fmt.Sprintf("%s/esc table • %s refresh • %s help • %s quit",
+			keys.Overview.Help().Key, keys.Refresh.Help().Key, keys.Help.Help().Key, keys.Quit.Help().Key))
+
+	leftW := w / 2
+	rightW := w - leftW
+	left := lipgloss.JoinVertical(lipgloss.Left,
+		m.overviewPanel("Status", m.renderStatusBreakdown(leftW-4), leftW),
+		m.overviewPanel("Phases", m.renderPhaseHistogram(leftW-4), leftW),
+		m.overviewPanel("ISC", m.renderISCRate(leftW-4), leftW))
+	right := lipgloss.JoinVertical(lipgloss.Left,
+		m.overviewPanel("Throughput", m.renderFleetThroughput(rightW-4), rightW),
+		m.overviewPanel("Tokens by Model", m.renderModelTokens(rightW-4), rightW))
+	sections := []string{lipgloss.JoinHorizontal(lipgloss.Top, left, right)}
+
+	sections = append(sections, lipgloss.JoinHorizontal(lipgloss.Top,
+		m.overviewPanel(fmt.Sprintf("Top %d by Cost", overviewTopN), m.renderTopAgents(leftW-4, true), leftW),
+		m.overviewPanel(fmt.Sprintf("Top %d by Tokens", overviewTopN), m.renderTopAgents(rightW-4, false), rightW)))
+	if len(m.phases.types()) > 0 {
+		sections = append(sections, m.overviewPanel("Phase Durations by Type", m.renderPhaseAverages(w-4), w))
+	}
+	body := lipgloss.JoinVertical(lipgloss.Left, sections...)
+
+	// Fit the terminal: drop whole rows of panels from the bottom, and only
+	// cut through the first when even that is too tall
+	if m.height > 0 {
+		room := m.height - lipgloss.Height(status) - lipgloss.Height(hint)
+		for _, s := range top {
+			room -= lipgloss.Height(s)
+		}
+		for len(sections) > 1 && lipgloss.Height(body) > room {
+			sections = sections[:len(sections)-1]
+			body = lipgloss.JoinVertical(lipgloss.Left, sections...)
+		}
+		if lines := strings.Split(body, "\n"); len(lines) > room {
+			body = strings.Join(lines[:max(room, 0)], "\n")
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, append(top, body, status, hint)...)
+}
+
+// overviewPanel boxes one chart; width includes the border.
+func (m model) overviewPanel(title, body string, width int) string {
+	head := lipgloss.NewStyle().Bold(true).Foreground(m.theme.Title).Render(title)
+	return lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(m.theme.Border).
+		Padding(0, 1).Width(width - 2).
+		Render(head + "\n" + body)
+}
+
+// hbar is a horizontal bar of width cells, filled in proportion to v/top.
+func hbar(v, top float64, width int, color lipgloss.TerminalColor, th Theme) string {
+	filled := 0
+	if top > 0 {
+		filled = int(v / top * float64(width))
+		if filled == 0 && v > 0 {
+			filled = 1 // keep small non-zero values visible
+		}
+	}
+	filled = clamp(filled, 0, width)
+	return lipgloss.NewStyle().Foreground(color).Render(strings.Repeat("█", filled)) +
+		lipgloss.NewStyle().Foreground(th.BarBg).Render(strings.Repeat("░", width-filled))
+}
+
+// renderStatusBreakdown is one bar per status, scaled to the fleet size.
+func (m model) renderStatusBreakdown(w int) string {
+	sum := summarize(m.agents)
+	barW := max(w-24, 5)
+	var lines []string
+	for st := StatusRunning; st <= StatusStopped; st++ {
+		n := sum.Counts[st]
+		pct := 0
+		if sum.Total > 0 {
+			pct = n * 100 / sum.Total
+		}
+		label := lipgloss.NewStyle().Foreground(m.theme.status(st)).Render(fmt.Sprintf("%-8s", st))
+		lines = append(lines, fmt.Sprintf("%s %s %3d %3d%%",
+			label, hbar(float64(n), float64(sum.Total), barW, m.theme.status(st), m.theme), n, pct))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderPhaseHistogram is a column per phase, Done included, as tall as the
+// number of agents in it.
+func (m model) renderPhaseHistogram(w int) string {
+	const height = 5
+	var counts [PhaseDone + 1]int
+	most := 0
+	for _, a := range m.agents {
+		if a.Status == StatusStopped {
+			continue
+		}
+		counts[a.Phase]++
+		most = max(most, counts[a.Phase])
+	}
+	colW := clamp(w/len(counts), 4, 8)
+	cell := func(s string) string { return lipgloss.PlaceHorizontal(colW, lipgloss.Center, s) }
+	bar := lipgloss.NewStyle().Foreground(m.theme.Accent)
+	dim := lipgloss.NewStyle().Foreground(m.theme.Dim)
+
+	rows := make([]string, height+2)
+	for p, n := range counts {
+		// Height in eighths of a line, so small counts still show
+		eighths := 0
+		if most > 0 {
+			eighths = n * height * 8 / most
+			if eighths == 0 && n > 0 {
+				eighths = 1
+			}
+		}
+		rows[0] += cell(dim.Render(fmt.Sprint(n)))
+		for line := 0; line < height; line++ {
+			fill := clamp(eighths-(height-1-line)*8, 0, 8)
+			s := " "
+			if fill > 0 {
+				s = string(sparkBlocks[fill-1])
+			}
+			rows[line+1] += cell(bar.Render(strings.Repeat(s, colW-2)))
+		}
+		rows[height+1] += cell(Phase(p).String()[:3])
+	}
+	return strings.Join(rows, "\n")
+}
+
+// renderISCRate is the share of ISC criteria passing across the fleet.
+func (m model) renderISCRate(w int) string {
+	passed, total, agents, complete := 0, 0, 0, 0
+	for _, a := range m.agents {
+		if len(a.ISCItems) == 0 {
+			continue
+		}
+		agents++
+		done := 0
+		for _, c := range a.ISCItems {
+			if c.Passed {
+				done++
+			}
+		}
+		passed += done
+		total += len(a.ISCItems)
+		if done == len(a.ISCItems) {
+			complete++
+		}
+	}
+	dim := lipgloss.NewStyle().Foreground(m.theme.Dim)
+	if total == 0 {
+		return dim.Render("No agents have ISC criteria.")
+	}
+	return renderProgressBar(passed*100/total, max(w-6, 10), m.theme) + "\n" +
+		dim.Render(fmt.Sprintf("%d/%d criteria passing across %d agents; %d with all passing",
+			passed, total, agents, complete))
+}
+
+// renderFleetThroughput charts total tok/s: the recent samples as a braille
+// chart, the last hour as a sparkline of per-minute averages.
+func (m model) renderFleetThroughput(w int) string {
+	label := lipgloss.NewStyle().Bold(true).Foreground(m.theme.Fg)
+	dim := lipgloss.NewStyle().Foreground(m.theme.Dim)
+	chart := lipgloss.NewStyle().Foreground(m.theme.Bar)
+
+	now := 0.0
+	for _, a := range m.agents {
+		now += shownTokRate(a)
+	}
+	vals := m.fleet.recent.values()
+	top := peak(vals)
+	cols := max(w-18, 10)
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s %.0f tok/s   %s %.0f\n", label.Render("Now:"), now, label.Render("Peak:"), top))
+	row := func(name, axis, line string) {
+		b.WriteString(fmt.Sprintf("%s %s %s\n",
+			label.Render(fmt.Sprintf("%-9s", name)), dim.Render(fmt.Sprintf("%5s", axis)), chart.Render(line)))
+	}
+	for i, line := range brailleChart(vals, cols, 4, top) {
+		switch i {
+		case 0:
+			row(fmtWindow(time.Duration(m.config.TickInterval)*historyLen)+":", fmt.Sprintf("%.0f", top), line)
+		case 3:
+			row("", "0", line)
+		default:
+			row("", "", line)
+		}
+	}
+	hour := m.fleet.hourly.values()
+	row("1 hour:", fmt.Sprintf("%.0f", peak(hour)), sparkline(hour, min(cols, historyLen), 0))
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// fmtWindow labels the span the recent samples cover, which depends on the
+// tick interval: "2 min" at the default.
+func fmtWindow(d time.Duration) string {
+	if d%time.Minute == 0 {
+		return fmt.Sprintf("%d min", int(d.Minutes()))
+	}
+	return fmtDuration(d)
+}
+
+// renderModelTokens is total tokens per model, largest first.
+func (m model) renderModelTokens(w int) string {
+	byModel := map[string]int{}
+	for _, a := range m.agents {
+		byModel[firstNonEmpty(a.Model, "unknown")] += a.TotalTokensIn + a.TotalTokensOut
+	}
+	names := sortedKeys(byModel)
+	sort.SliceStable(names, func(i, j int) bool { return byModel[names[i]] > byModel[names[j]] })
+	if len(names) == 0 {
+		return lipgloss.NewStyle().Foreground(m.theme.Dim).Render("No agents.")
+	}
+	nameW := 0
+	for _, n := range names {
+		nameW = max(nameW, len(n))
+	}
+	nameW = min(nameW, 20)
+	top := float64(byModel[names[0]])
+	barW := max(w-nameW-10, 5)
+	var lines []string
+	for _, n := range names {
+		lines = append(lines, fmt.Sprintf("%-*s %s %7s", nameW, truncate(n, nameW),
+			hbar(float64(byModel[n]), top, barW, m.theme.Bar, m.theme), fmtTokens(byModel[n])))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderTopAgents lists the agents that cost the most, or used the most
+// tokens, with bars scaled to the first.
+func (m model) renderTopAgents(w int, byCost bool) string {
+	value := func(a Agent) float64 {
+		if byCost {
+			return m.pricing.Cost(a)
+		}
+		return float64(a.TotalTokensIn + a.TotalTokensOut)
+	}
+	ranked := append([]Agent(nil), m.agents...)
+	sort.SliceStable(ranked, func(i, j int) bool { return value(ranked[i]) > value(ranked[j]) })
+	ranked = ranked[:min(len(ranked), overviewTopN)]
+	if len(ranked) == 0 {
+		return lipgloss.NewStyle().Foreground(m.theme.Dim).Render("No agents.")
+	}
+	top := value(ranked[0])
+	barW := max(w-46, 5)
+	var lines []string
+	for i, a := range ranked {
+		v := fmtTokens(int(value(a)))
+		if byCost {
+			v = fmtCost(value(a))
+		}
+		name := fmt.Sprintf("%-16s", truncate(a.Name, 16))
+		if byCost && m.pricing.OverBudget(a) {
+			name = lipgloss.NewStyle().Foreground(m.theme.Error).Bold(true).Render(name)
+		}
+		lines = append(lines, fmt.Sprintf("%d. %-8s %s %8s %s", i+1, a.ID, name, v,
+			hbar(value(a), top, barW, m.theme.Accent, m.theme)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderPhaseAverages is a row per agent type with its average time in each
+// phase, the slowest highlighted.
+func (m model) renderPhaseAverages(w int) string {
+	dim := lipgloss.NewStyle().Foreground(m.theme.Dim)
+	slow := lipgloss.NewStyle().Bold(true).Foreground(m.theme.Running)
+	colW := clamp((w-18)/int(PhaseDone), 7, 10)
+
+	head := fmt.Sprintf("%-18s", "")
+	for p := PhaseObserve; p <= PhaseLearn; p++ {
+		head += fmt.Sprintf("%-*s", colW, p.String()[:3])
+	}
+	lines := []string{dim.Render(head)}
+	for _, name := range m.phases.types() {
+		avg, _ := m.phases.averages(name)
+		slowest := PhaseObserve
+		for p := PhaseObserve; p <= PhaseLearn; p++ {
+			if avg[p].mean() > avg[slowest].mean() {
+				slowest = p
+			}
+		}
+		line := fmt.Sprintf("%-18s", truncate(name, 17))
+		for p := PhaseObserve; p <= PhaseLearn; p++ {
+			s := fmt.Sprintf("%-*s", colW, "--")
+			if avg[p].n > 0 {
+				s = fmt.Sprintf("%-*s", colW, fmtDuration(avg[p].mean().Round(time.Second)))
+				if p == slowest {
+					s = slow.Render(s)
+				}
+			}
+			line += s
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,355 @@
+package main
+
+// Event log pager — the selected agent's whole event log, full screen.
+//
+// Without --event-log-dir the pager shows what the source keeps in memory
+// (the last event_log_cap entries); with it, the agent's file on disk. It
+// scrolls with the usual movement keys and counts, searches with / and
+// n/N, and follows the tail while scrolled to the end (f toggles that).
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// logPager is the open pager. It is owned by the model, like spawnForm.
+// Logs on disk can be long, so only the lines on screen are styled, and
+// new lines are searched as they arrive rather than the whole log again.
+type logPager struct {
+	id, name string
+	path     string   // the agent's file; "" when history is in memory only
+	lines    []string // everything loaded so far
+	read     int64    // bytes of path already in lines
+	err      string
+
+	top    int  // first line on screen
+	height int  // lines on screen
+	follow bool // stay at the end as lines arrive
+
+	searching bool // search prompt has focus
+	input     textinput.Model
+	query     string // lower case
+	matches   []int  // indexes into lines
+	match     int    // current entry of matches
+	searched  int    // lines already searched for query
+}
+
+// bottom is the largest top that still fills the screen.
+func (p *logPager) bottom() int {
+	return max(len(p.lines)-p.height, 0)
+}
+
+func (p *logPager) atBottom() bool {
+	return p.top >= p.bottom()
+}
+
+// scroll moves the window by delta lines.
+func (p *logPager) scroll(delta int) {
+	p.top = clamp(p.top+delta, 0, p.bottom())
+}
+
+// setLines replaces the log. appended means lines only grew at the end, so
+// earlier matches still hold.
+func (p *logPager) setLines(lines []string, appended bool) {
+	if !appended {
+		p.matches, p.searched = p.matches[:0], 0
+	}
+	p.lines = lines
+	p.search()
+	if p.follow {
+		p.top = p.bottom()
+	}
+	p.top = min(p.top, p.bottom())
+}
+
+// setQuery starts a new search.
+func (p *logPager) setQuery(q string) {
+	p.query = strings.ToLower(q)
+	p.matches, p.searched, p.match = p.matches[:0], 0, 0
+	p.search()
+}
+
+// search looks for the query in the lines not searched yet.
+func (p *logPager) search() {
+	if p.query != "" {
+		for i := p.searched; i < len(p.lines); i++ {
+			if strings.Contains(strings.ToLower(p.lines[i]), p.query) {
+				p.matches = append(p.matches, i)
+			}
+		}
+	}
+	p.searched = len(p.lines)
+	p.match = min(p.match, max(len(p.matches)-1, 0))
+}
+
+// logLoadedMsg carries lines read from an agent's event log file.
+type logLoadedMsg struct {
+	id     string
+	lines  []string
+	offset int64
+	err    error
+}
+
+// loadLog reads the file from offset on.
+func loadLog(id, path string, offset int64) tea.Cmd {
+	return func() tea.Msg {
+		lines, off, err := readLines(path, offset)
+		return logLoadedMsg{id: id, lines: lines, offset: off, err: err}
+	}
+}
+
+// openPager shows the selected agent's log, starting at the end.
+func (m *model) openPager() tea.Cmd {
+	a, ok := m.selected()
+	if !ok {
+		return nil
+	}
+	p := &logPager{id: a.ID, name: a.Name, follow: true}
+	m.pager = p
+	if m.events != nil {
+		p.path = m.events.path(a.ID)
+		m.resizePager()
+		return loadLog(p.id, p.path, 0)
+	}
+	m.resizePager()
+	p.setLines(a.EventLog, false)
+	return nil
+}
+
+// pagerUpdated pulls in new entries after a source update.
+func (m *model) pagerUpdated() tea.Cmd {
+	p := m.pager
+	if p.path != "" {
+		return loadLog(p.id, p.path, p.read)
+	}
+	for _, a := range m.agents {
+		if a.ID == p.id {
+			p.setLines(a.EventLog, false)
+		}
+	}
+	return nil
+}
+
+// pagerLoaded appends lines read from disk.
+func (m *model) pagerLoaded(msg logLoadedMsg) {
+	p := m.pager
+	if p == nil || msg.id != p.id {
+		return
+	}
+	p.err = ""
+	if msg.err != nil {
+		p.err = msg.err.Error()
+	}
+	if msg.offset > p.read {
+		p.setLines(append(p.lines, msg.lines...), true)
+		p.read = msg.offset
+	}
+}
+
+// resizePager fits the log between the title and status lines.
+func (m *model) resizePager() {
+	p := m.pager
+	h := m.height
+	if m.width == 0 {
+		h = 40
+	}
+	p.height = max(h-3, 1)
+	if p.follow {
+		p.top = p.bottom()
+	}
+	p.top = min(p.top, p.bottom())
+}
+
+// renderLog styles the lines on screen, with search highlights.
+func (m model) renderLog(w int) string {
+	p := m.pager
+	hl := lipgloss.NewStyle().Reverse(true)
+	cur := lipgloss.NewStyle().Foreground(m.theme.Accent).Bold(true)
+
+	current := -1
+	if len(p.matches) > 0 {
+		current = p.matches[p.match]
+	}
+	end := min(p.top+p.height, len(p.lines))
+	rows := make([]string, 0, end-p.top)
+	for i := p.top; i < end; i++ {
+		l, gutter := p.lines[i], "  "
+		if p.query != "" && strings.Contains(strings.ToLower(l), p.query) {
+			if i == current {
+				gutter = cur.Render("▸ ")
+			}
+			l = highlight(l, p.query, hl)
+		}
+		rows = append(rows, gutter+l)
+	}
+	return lipgloss.NewStyle().Width(w).Height(p.height).MaxWidth(w).MaxHeight(p.height).
+		Render(strings.Join(rows, "\n"))
+}
+
+// highlight marks every case-insensitive occurrence of q (already lower case).
+func highlight(s, q string, st lipgloss.Style) string {
+	low := strings.ToLower(s)
+	if len(low) != len(s) {
+		return s // case folding changed the byte offsets
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(low, q)
+		if i < 0 {
+			break
+		}
+		b.WriteString(s[:i] + st.Render(s[i:i+len(q)]))
+		s, low = s[i+len(q):], low[i+len(q):]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// jumpToMatch moves match by delta, wrapping, and scrolls it into view.
+func (m *model) jumpToMatch(delta int) {
+	p := m.pager
+	if len(p.matches) == 0 {
+		m.notice = fmt.Sprintf("pattern not found: %s", p.query)
+		return
+	}
+	p.match = (p.match + delta + len(p.matches)) % len(p.matches)
+	p.follow = false
+	if line := p.matches[p.match]; line < p.top || line >= p.top+p.height {
+		p.top = clamp(line-p.height/2, 0, p.bottom())
+	}
+}
+
+// updatePager handles keys while the pager is open.
+func (m model) updatePager(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.pager
+	if p.searching {
+		switch msg.Type {
+		case tea.KeyEnter:
+			p.searching = false
+			p.setQuery(p.input.Value())
+			// Start from the first match at or below the top of the screen
+			for i, line := range p.matches {
+				if line >= p.top {
+					p.match = i
+					break
+				}
+			}
+			m.jumpToMatch(0)
+			return m, nil
+		case tea.KeyEsc:
+			p.searching = false
+			return m, nil
+		}
+		var cmd tea.Cmd
+		p.input, cmd = p.input.Update(msg)
+		return m, cmd
+	}
+
+	if m.takeCount(msg) {
+		return m, nil
+	}
+	n, _ := m.popCount()
+	switch {
+	case msg.Type == tea.KeyCtrlC:
+		return m, tea.Quit
+	case key.Matches(msg, keys.Log, keys.Quit), msg.Type == tea.KeyEsc:
+		m.pager = nil
+		return m, nil
+	case key.Matches(msg, keys.Filter):
+		p.searching = true
+		p.input = textinput.New()
+		p.input.Prompt = "/"
+		p.input.SetValue(p.query)
+		p.input.Cursor.SetMode(cursor.CursorStatic)
+		p.input.Focus()
+		return m, nil
+	case msg.String() == "n":
+		m.jumpToMatch(n)
+	case msg.String() == "N":
+		m.jumpToMatch(-n)
+	case msg.String() == "f":
+		p.follow = !p.follow
+		if p.follow {
+			p.top = p.bottom()
+		}
+		return m, nil
+	case key.Matches(msg, keys.Up):
+		p.scroll(-n)
+	case key.Matches(msg, keys.Down):
+		p.scroll(n)
+	case key.Matches(msg, keys.HalfUp):
+		p.scroll(-n * max(p.height/2, 1))
+	case key.Matches(msg, keys.HalfDown):
+		p.scroll(n * max(p.height/2, 1))
+	case msg.Type == tea.KeyPgUp:
+		p.scroll(-n * p.height)
+	case msg.Type == tea.KeyPgDown:
+		p.scroll(n * p.height)
+	case key.Matches(msg, keys.Top):
+		p.top = 0
+	case key.Matches(msg, keys.Bottom):
+		p.top = p.bottom()
+	}
+	p.follow = p.atBottom()
+	return m, nil
+}
+
+// renderPager draws the pager full screen.
+func (m model) renderPager(w int) string {
+	p := m.pager
+	title := lipgloss.NewStyle().Bold(true).Foreground(m.theme.Title)
+	dim := lipgloss.NewStyle().Foreground(m.theme.Dim)
+	accent := lipgloss.NewStyle().Foreground(m.theme.Accent).Bold(true)
+
+	source := fmt.Sprintf("last %d in memory", len(p.lines))
+	if p.path != "" {
+		source = p.path
+	}
+	head := title.Render(fmt.Sprintf("Event log · %s (%s)", p.id, p.name)) +
+		dim.Render(fmt.Sprintf("  %d lines · %s", len(p.lines), source))
+
+	var status []string
+	if p.follow {
+		status = append(status, accent.Render("FOLLOW"))
+	}
+	if p.query != "" {
+		found := "no matches"
+		if len(p.matches) > 0 {
+			found = fmt.Sprintf("match %d/%d", p.match+1, len(p.matches))
+		}
+		status = append(status, fmt.Sprintf("/%s  %s", p.query, found))
+	}
+	if len(p.lines) > 0 {
+		pct := 100
+		if b := p.bottom(); b > 0 {
+			pct = p.top * 100 / b
+		}
+		status = append(status, fmt.Sprintf("%d%%", pct))
+	}
+	if p.err != "" {
+		status = append(status, lipgloss.NewStyle().Foreground(m.theme.Error).Render(p.err))
+	}
+	foot := strings.Join(status, "  │  ")
+	if p.searching {
+		foot = p.input.View()
+	}
+	hint := dim.Render(fmt.Sprintf("%s search • n/N next/prev • f follow • %s/esc close",
+		keys.Filter.Help().Key, keys.Log.Help().Key))
+	gap := max(w-lipgloss.Width(foot)-lipgloss.Width(hint)-2, 1)
+
+	body := m.renderLog(w)
+	if len(p.lines) == 0 {
+		body = lipgloss.NewStyle().Height(p.height).Render(dim.Render("  No events yet."))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		" "+head,
+		lipgloss.NewStyle().Foreground(m.theme.Border).Render(strings.Repeat("─", w)),
+		body,
+		" "+foot+strings.Repeat(" ", gap)+hint)
+}
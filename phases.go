@@ -0,0 +1,295 @@
+package main
+
+// Phase timing — how long each agent spends in each Algorithm phase.
+//
+// Sources record every visit to a phase on the agent as a PhaseSpan, with
+// its entry and exit time on the source's own clock (event timestamps for
+// the live source, the recording's for a replay) and the tokens spent
+// during it, so the timings reach --json and recordings with the rest of the
+// agent. A run starts over when the agent goes back to an earlier phase. The
+// model folds spans into averages by agent type as they close, kept after
+// the agents themselves are gone, which show where a kind of agent keeps
+// stalling. Replays are left out of the averages, since seeking would count
+// the same spans again.
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// phaseSpanCap bounds the spans kept per agent, across runs.
+const phaseSpanCap = 32
+
+// PhaseSpan is one visit to a phase.
+type PhaseSpan struct {
+	Phase   Phase
+	Start   time.Time
+	End     time.Time // zero while the agent is still in the phase
+	Tokens  int       // input and output tokens spent during the span
+	Partial bool      // already under way when the source first saw the agent
+}
+
+// timed reports whether the agent's current phase is being timed.
+func (a Agent) timed() bool {
+	return a.Phase < PhaseDone && a.Status != StatusStopped
+}
+
+// openSpan returns the span the agent is in, if any.
+func openSpan(a *Agent) *PhaseSpan {
+	if n := len(a.Phases); n > 0 && a.Phases[n-1].End.IsZero() {
+		return &a.Phases[n-1]
+	}
+	return nil
+}
+
+// beginPhases starts timing an agent its source has just come across;
+// partial marks a phase that was already under way.
+func beginPhases(a *Agent, now time.Time, partial bool) {
+	a.Phases = nil
+	if a.timed() {
+		a.Phases = []PhaseSpan{{Phase: a.Phase, Start: now, Partial: partial}}
+	}
+}
+
+// trackPhase updates an agent's spans after a change, the way trackRetry
+// updates its retry count: prev is the agent before the change and now is
+// when it happened on the source's clock.
+func trackPhase(a *Agent, prev Agent, now time.Time) {
+	o := openSpan(a)
+	if o != nil {
+		o.Tokens += max(a.TotalTokensIn+a.TotalTokensOut-prev.TotalTokensIn-prev.TotalTokensOut, 0)
+		if a.timed() && o.Phase == a.Phase {
+			return
+		}
+		o.End = now
+	}
+	if a.timed() {
+		a.Phases = append(a.Phases, PhaseSpan{Phase: a.Phase, Start: now})
+		if n := len(a.Phases); n > phaseSpanCap {
+			a.Phases = append([]PhaseSpan(nil), a.Phases[n-phaseSpanCap:]...)
+		}
+	}
+}
+
+// endPhases closes the open span, if any, for an agent that stopped at t.
+func endPhases(a *Agent, t time.Time) {
+	if o := openSpan(a); o != nil {
+		o.End = t
+	}
+}
+
+// currentRun returns the spans since the agent last went back to an earlier
+// phase or started over. Within a run every span is a later phase than the
+// one before it.
+func currentRun(spans []PhaseSpan) []PhaseSpan {
+	i := len(spans) - 1
+	for i > 0 && spans[i-1].Phase < spans[i].Phase {
+		i--
+	}
+	return spans[max(i, 0):]
+}
+
+// phaseAvg accumulates finished spans of one phase.
+type phaseAvg struct {
+	n     int
+	total time.Duration
+}
+
+func (a phaseAvg) mean() time.Duration {
+	if a.n == 0 {
+		return 0
+	}
+	return a.total / time.Duration(a.n)
+}
+
+// phaseStats keeps the averages. It is owned by the model: observe is called
+// from applyUpdate with each agent's previous and new state.
+type phaseStats struct {
+	avg map[string]*[PhaseDone]phaseAvg // by agent type
+}
+
+func newPhaseStats() *phaseStats {
+	return &phaseStats{avg: map[string]*[PhaseDone]phaseAvg{}}
+}
+
+// observe adds the spans that closed between two versions of an agent.
+// prev is the zero Agent the first time an agent is seen.
+func (t *phaseStats) observe(prev, next Agent) {
+	type spanKey struct {
+		phase      Phase
+		start, end int64
+	}
+	closed := make(map[spanKey]bool, len(prev.Phases))
+	for _, s := range prev.Phases {
+		if !s.End.IsZero() {
+			closed[spanKey{s.Phase, s.Start.UnixNano(), s.End.UnixNano()}] = true
+		}
+	}
+	for _, s := range next.Phases {
+		if !s.End.IsZero() && !closed[spanKey{s.Phase, s.Start.UnixNano(), s.End.UnixNano()}] {
+			t.add(next.Name, s)
+		}
+	}
+}
+
+// add counts a closed span for its agent type. Partial spans would
+// understate the phase, so they are left out.
+func (t *phaseStats) add(name string, s PhaseSpan) {
+	if s.Partial || s.Phase >= PhaseDone {
+		return
+	}
+	avg, ok := t.avg[name]
+	if !ok {
+		avg = &[PhaseDone]phaseAvg{}
+		t.avg[name] = avg
+	}
+	avg[s.Phase].n++
+	avg[s.Phase].total += s.End.Sub(s.Start)
+}
+
+// phaseTotal is the time and tokens an agent spent in one phase this run.
+type phaseTotal struct {
+	spent   time.Duration
+	tokens  int
+	visited bool
+	partial bool // includes a span the source did not see begin
+}
+
+// phaseTotals sums a run's spans by phase, counting the open one up to now.
+func phaseTotals(run []PhaseSpan, now time.Time) (out [PhaseDone]phaseTotal) {
+	for _, s := range run {
+		if s.Phase >= PhaseDone {
+			continue
+		}
+		end := s.End
+		if end.IsZero() {
+			end = now
+		}
+		pt := &out[s.Phase]
+		pt.spent += max(end.Sub(s.Start), 0)
+		pt.tokens += s.Tokens
+		pt.visited = true
+		pt.partial = pt.partial || s.Partial
+	}
+	return out
+}
+
+// longest is the phase with the most time in totals, or false when none
+// has any.
+func longest(totals [PhaseDone]phaseTotal) (Phase, bool) {
+	best, found := PhaseObserve, false
+	for p, pt := range totals {
+		if pt.visited && pt.spent > 0 && (!found || pt.spent > totals[best].spent) {
+			best, found = Phase(p), true
+		}
+	}
+	return best, found
+}
+
+// averages returns the mean time per phase for an agent type.
+func (t *phaseStats) averages(name string) ([PhaseDone]phaseAvg, bool) {
+	avg, ok := t.avg[name]
+	if !ok {
+		return [PhaseDone]phaseAvg{}, false
+	}
+	return *avg, true
+}
+
+// types lists the agent types with averages, by name.
+func (t *phaseStats) types() []string {
+	names := make([]string, 0, len(t.avg))
+	for name := range t.avg {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// renderPhaseTimeline draws the detail pane's timeline: each phase with the
+// time and tokens the agent spent in it, the longest in the warning color,
+// and the fleet's average for the agent's type underneath.
+func (m model) renderPhaseTimeline(a Agent, w int) string {
+	title := lipgloss.NewStyle().Bold(true).Foreground(m.theme.Title)
+	dim := lipgloss.NewStyle().Foreground(m.theme.Dim)
+	pass := lipgloss.NewStyle().Foreground(m.theme.Idle)
+	slow := lipgloss.NewStyle().Bold(true).Foreground(m.theme.Running)
+	current := lipgloss.NewStyle().Bold(true).Foreground(m.theme.Accent)
+
+	totals := phaseTotals(currentRun(a.Phases), time.Now())
+	top, hasTop := longest(totals)
+
+	var b strings.Builder
+	head := title.Render("Phase Timeline")
+	if hasTop {
+		head += dim.Render(fmt.Sprintf("  longest: %s %s", top, fmtSpent(totals[top])))
+	}
+	b.WriteString(head + "\n")
+
+	var cells, toks []string
+	for p := PhaseObserve; p <= PhaseLearn; p++ {
+		pt := totals[p]
+		cell := p.Icon() + " " + p.String()[:3]
+		if pt.visited {
+			cell += " " + fmtSpent(pt)
+		}
+		style := dim
+		switch {
+		case hasTop && p == top:
+			style = slow
+		case p == a.Phase:
+			style = current
+		case p < a.Phase:
+			style = pass
+		}
+		if p == a.Phase {
+			cell = "▶" + cell
+		}
+		cell = style.Render(cell)
+		tok := ""
+		if pt.visited {
+			tok = fmtTokens(pt.tokens) + " tok"
+		}
+		cells = append(cells, cell)
+		toks = append(toks, tok+strings.Repeat(" ", max(lipgloss.Width(cell)-lipgloss.Width(tok), 0)))
+	}
+	b.WriteString("  " + strings.Join(cells, " → ") + "\n")
+	if hasTop {
+		b.WriteString(dim.Render("  "+strings.Join(toks, "   ")) + "\n")
+	}
+
+	// The fleet's averages for this agent's type
+	if avg, ok := m.phases.averages(a.Name); ok {
+		var parts []string
+		slowest := PhaseObserve
+		for p := PhaseObserve; p <= PhaseLearn; p++ {
+			if avg[p].mean() > avg[slowest].mean() {
+				slowest = p
+			}
+		}
+		for p := PhaseObserve; p <= PhaseLearn; p++ {
+			s := p.String()[:3] + " --"
+			if avg[p].n > 0 {
+				s = p.String()[:3] + " " + fmtDuration(avg[p].mean().Round(time.Second))
+			}
+			if avg[p].n > 0 && p == slowest {
+				s = slow.Render(s)
+			}
+			parts = append(parts, s)
+		}
+		b.WriteString(dim.Render(fmt.Sprintf("  %s avg: ", a.Name)) + strings.Join(parts, dim.Render(" · ")) + "\n")
+	}
+	return b.String()
+}
+
+// fmtSpent is a phase's time, "≥" when it began before the source saw it.
+func fmtSpent(pt phaseTotal) string {
+	s := fmtDuration(pt.spent.Round(time.Second))
+	if pt.partial {
+		s = "≥" + s
+	}
+	return s
+}
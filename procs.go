@@ -0,0 +1,455 @@
+package main
+
+// Process control — start, pause, resume and stop real agent processes.
+//
+// procSource wraps another AgentSource. Agents it has spawned are "managed":
+// their status comes from the process (running, SIGSTOP'd, exited) rather
+// than from the inner source, and control actions become signals. Actions on
+// unmanaged agents fall through to the inner source unchanged.
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+const defaultStopGrace = 5 * time.Second
+
+// agentProc is one spawned agent process.
+type agentProc struct {
+	cmd      *exec.Cmd
+	status   AgentStatus
+	exitCode int
+	exitedAt time.Time
+	stopping bool           // stop requested; exit is expected, not an error
+	exited   chan struct{}  // closed once Wait returns
+	stdin    io.WriteCloser // answers to the agent's questions
+	stderr   *stderrTail
+
+	// Carried over when the agent is started again
+	errors  []AgentError
+	retries int
+}
+
+func (p *agentProc) alive() bool {
+	select {
+	case <-p.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// procSource decorates an AgentSource with real process control.
+type procSource struct {
+	inner   AgentSource
+	command string        // run via "sh -c" with PAI_AGENT_* in the environment
+	grace   time.Duration // SIGTERM → SIGKILL delay
+
+	mu    sync.Mutex
+	procs map[string]*agentProc
+	last  map[string]Agent // latest state from the inner source, by ID
+
+	changed chan string // agent IDs whose process state changed
+	updates chan AgentUpdate
+	done    chan struct{}
+}
+
+func newProcSource(inner AgentSource, command string, grace time.Duration) *procSource {
+	if grace <= 0 {
+		grace = defaultStopGrace
+	}
+	return &procSource{
+		inner:   inner,
+		command: command,
+		grace:   grace,
+		procs:   map[string]*agentProc{},
+		last:    map[string]Agent{},
+		changed: make(chan string, 16),
+		updates: make(chan AgentUpdate, 16),
+		done:    make(chan struct{}),
+	}
+}
+
+func (s *procSource) Start() {
+	for _, a := range s.inner.Snapshot() {
+		s.last[a.ID] = a
+	}
+	s.inner.Start()
+	go s.run()
+}
+
+// Stop terminates every managed process that is still alive, then stops the
+// inner source. Processes the dashboard spawned do not outlive it.
+func (s *procSource) Stop() {
+	s.mu.Lock()
+	var alive []*agentProc
+	for _, p := range s.procs {
+		if p.alive() {
+			p.stopping = true
+			alive = append(alive, p)
+		}
+	}
+	s.mu.Unlock()
+	var wg sync.WaitGroup
+	for _, p := range alive {
+		wg.Add(1)
+		go func(p *agentProc) {
+			defer wg.Done()
+			s.terminate(p)
+		}(p)
+	}
+	wg.Wait()
+
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+	s.inner.Stop()
+}
+
+func (s *procSource) Snapshot() []Agent {
+	agents := s.inner.Snapshot()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range agents {
+		s.overlay(&agents[i])
+	}
+	return agents
+}
+
+func (s *procSource) Updates() <-chan AgentUpdate { return s.updates }
+
+func (s *procSource) Refresh() { s.inner.Refresh() }
+
+// Unwrap returns the source whose agents get processes.
+func (s *procSource) Unwrap() AgentSource { return s.inner }
+
+func (s *procSource) Control(id string, action ControlAction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.procs[id]
+	managed := p != nil && p.alive()
+
+	switch action {
+	case ActionStart:
+		if managed {
+			return errors.New("already running")
+		}
+		a, ok := s.last[id]
+		if !ok {
+			return fmt.Errorf("agent %s not found", id)
+		}
+		return s.spawn(a)
+	case ActionStop:
+		if !managed {
+			return s.inner.Control(id, action)
+		}
+		if p.stopping {
+			return errors.New("already stopping")
+		}
+		p.stopping = true
+		go s.terminate(p)
+		return nil
+	case ActionPause, ActionResume:
+		if !managed {
+			return s.inner.Control(id, action)
+		}
+		sig, status := sigStop, StatusPaused
+		if action == ActionResume {
+			sig, status = sigCont, StatusRunning
+		}
+		if err := signalProc(p.cmd.Process, sig); err != nil {
+			return err
+		}
+		p.status = status
+		s.notify(id)
+		return nil
+	}
+	return errUnsupported
+}
+
+// CanRestart holds for agents the dashboard has run a process for; restarting
+// one spawns it again. Agents it never started are left to their own owners.
+func (s *procSource) CanRestart(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.procs[id]
+	return ok
+}
+
+// Answer writes the answer to a managed process's standard input, one line
+// per answer, and tells the inner source if it can take answers too. Agents
+// without a process of their own are answered by the inner source alone.
+func (s *procSource) Answer(id, answer string) error {
+	an, ok := s.inner.(Answerer)
+	s.mu.Lock()
+	p := s.procs[id]
+	if p == nil || !p.alive() {
+		s.mu.Unlock()
+		if !ok {
+			return errUnsupported
+		}
+		return an.Answer(id, answer)
+	}
+	a, found := s.last[id]
+	if !found || !a.waiting() {
+		s.mu.Unlock()
+		return errNotWaiting
+	}
+	s.mu.Unlock()
+
+	if _, err := io.WriteString(p.stdin, oneLine(answer)+"\n"); err != nil {
+		return fmt.Errorf("answer: %w", err)
+	}
+	if ok {
+		_ = an.Answer(id, answer) // the process has it; the inner source only mirrors it
+	}
+
+	s.mu.Lock()
+	if a, found := s.last[id]; found && a.waiting() {
+		now := time.Now()
+		a = copyAgent(a)
+		a.Status = StatusRunning
+		a.Question = AgentQuestion{}
+		a.LastActivity = "answered: " + truncate(oneLine(answer), 60)
+		a.LastActTime = now
+		a.EventLog = appendEvent(a.EventLog, now, a.LastActivity)
+		s.last[id] = a
+	}
+	s.mu.Unlock()
+	s.notify(id)
+	return nil
+}
+
+// Spawn creates the agent in the inner source and immediately starts its
+// process. If the process cannot start, the agent is stopped again.
+func (s *procSource) Spawn(req SpawnRequest) (Agent, error) {
+	sp, ok := s.inner.(Spawner)
+	if !ok {
+		return Agent{}, errUnsupported
+	}
+	a, err := sp.Spawn(req)
+	if err != nil {
+		return Agent{}, err
+	}
+	s.mu.Lock()
+	s.last[a.ID] = a
+	if err := s.spawn(a); err != nil {
+		delete(s.last, a.ID)
+		s.mu.Unlock()
+		// Don't leave a row behind that looks like it is running
+		_ = s.inner.Control(a.ID, ActionStop)
+		return Agent{}, err
+	}
+	s.overlay(&a)
+	s.mu.Unlock()
+	return a, nil
+}
+
+// spawn starts the configured command for an agent. Callers hold s.mu.
+func (s *procSource) spawn(a Agent) error {
+	cmd := exec.Command("sh", "-c", s.command)
+	cmd.Env = append(os.Environ(),
+		"PAI_AGENT_ID="+a.ID,
+		"PAI_AGENT_NAME="+a.Name,
+		"PAI_AGENT_MODEL="+a.Model,
+		"PAI_AGENT_TASK="+a.TaskDesc,
+	)
+	if a.WorkDir != "" {
+		cmd.Dir = a.WorkDir
+	}
+	tail := &stderrTail{}
+	cmd.Stdout, cmd.Stderr = io.Discard, tail // keep the TUI clean
+	cmd.WaitDelay = time.Second               // don't hang on a leftover child holding stderr open
+	cmd.SysProcAttr = procAttr()
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("spawn: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("spawn: %w", err)
+	}
+	p := &agentProc{cmd: cmd, status: StatusRunning, exited: make(chan struct{}), stdin: stdin, stderr: tail}
+	if old := s.procs[a.ID]; old != nil {
+		p.errors, p.retries = old.errors, old.retries
+		if old.status == StatusError {
+			p.retries++
+		}
+	}
+	s.procs[a.ID] = p
+	go s.wait(a.ID, p)
+	s.notify(a.ID)
+	return nil
+}
+
+// wait reaps a process and records how it ended.
+func (s *procSource) wait(id string, p *agentProc) {
+	err := p.cmd.Wait()
+	s.mu.Lock()
+	p.exitCode = p.cmd.ProcessState.ExitCode()
+	p.exitedAt = time.Now()
+	switch {
+	case p.stopping:
+		p.status = StatusStopped
+	case err != nil:
+		p.status = StatusError
+		e := AgentError{Message: err.Error(), ExitCode: p.exitCode, Stderr: p.stderr.lines(), Time: time.Now()}
+		if len(e.Stderr) > 0 {
+			e.Message = e.Stderr[len(e.Stderr)-1] // the exit code is shown on its own
+		}
+		d := Agent{Errors: p.errors, Retries: p.retries}
+		addError(&d, e)
+		p.errors = d.Errors
+	default:
+		p.status = StatusIdle
+		p.retries = 0
+	}
+	close(p.exited)
+	s.mu.Unlock()
+	s.notify(id)
+}
+
+// terminate sends SIGTERM, then SIGKILL if the process is still around
+// after the grace period. A paused process is continued first so it can
+// handle the SIGTERM.
+func (s *procSource) terminate(p *agentProc) {
+	_ = signalProc(p.cmd.Process, sigCont)
+	_ = signalProc(p.cmd.Process, sigTerm)
+	select {
+	case <-p.exited:
+	case <-time.After(s.grace):
+		_ = killProc(p.cmd.Process)
+		<-p.exited
+	}
+}
+
+// overlay replaces an agent's status with its process state, and adds the
+// process's failures to those the inner source reported. Once the process
+// has exited, newer activity from the inner source takes over. A live process
+// that the inner source reports waiting on a question stays Paused with it
+// until it is answered. Callers hold s.mu.
+func (s *procSource) overlay(a *Agent) {
+	p := s.procs[a.ID]
+	if p == nil {
+		return
+	}
+	if !p.alive() && a.LastActTime.After(p.exitedAt) {
+		// The inner source has heard from the agent since: it is its state now
+		a.Errors = mergeErrors(a.Errors, p.errors)
+		return
+	}
+	a.PID = p.cmd.Process.Pid
+	asking := (p.status == StatusRunning || p.status == StatusPaused) && !p.stopping && a.waiting()
+	if !asking {
+		a.Status = p.status
+		a.Question = AgentQuestion{}
+	}
+	a.ExitCode = p.exitCode
+	if !p.alive() && openSpan(a) != nil {
+		a.Phases = append([]PhaseSpan(nil), a.Phases...) // shared with s.last
+		endPhases(a, p.exitedAt)
+	}
+	a.Errors = mergeErrors(a.Errors, p.errors)
+	if p.retries > 0 {
+		a.Retries = p.retries
+	}
+	switch a.Status {
+	case StatusRunning:
+		if p.stopping {
+			a.LastActivity = "stopping…"
+		}
+	case StatusIdle:
+		a.Phase, a.Progress = PhaseDone, 100
+		a.TokensPerSec = 0
+	default:
+		a.TokensPerSec = 0
+	}
+}
+
+// stderrTail keeps the end of a process's standard error for its
+// AgentError.
+type stderrTail struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+// stderrTailBytes bounds stderrTail; only the last few lines are shown.
+const stderrTailBytes = 4096
+
+func (t *stderrTail) Write(b []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, b...)
+	if n := len(t.buf); n > stderrTailBytes {
+		t.buf = append([]byte(nil), t.buf[n-stderrTailBytes:]...)
+	}
+	return len(b), nil
+}
+
+func (t *stderrTail) lines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return tailText(string(t.buf), stderrTailLines)
+}
+
+func (s *procSource) notify(id string) {
+	select {
+	case s.changed <- id:
+	default:
+		go func() {
+			select {
+			case s.changed <- id:
+			case <-s.done:
+			}
+		}()
+	}
+}
+
+func (s *procSource) run() {
+	defer close(s.updates)
+	in := s.inner.Updates()
+	for {
+		var u AgentUpdate
+		select {
+		case <-s.done:
+			return
+		case iu, ok := <-in:
+			if !ok {
+				return
+			}
+			s.mu.Lock()
+			for i := range iu.Agents {
+				s.last[iu.Agents[i].ID] = iu.Agents[i]
+				s.overlay(&iu.Agents[i])
+			}
+			for _, id := range iu.Removed {
+				delete(s.last, id)
+			}
+			s.mu.Unlock()
+			u = iu
+		case id := <-s.changed:
+			s.mu.Lock()
+			a, ok := s.last[id]
+			if ok {
+				a = copyAgent(a)
+				s.overlay(&a)
+			}
+			s.mu.Unlock()
+			if !ok {
+				continue
+			}
+			u = AgentUpdate{Agents: []Agent{a}}
+		}
+		select {
+		case s.updates <- u:
+		case <-s.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,30 @@
+//go:build !unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// Job control signals do not exist here: pause/resume report errUnsupported
+// and stop falls back to an immediate kill.
+var (
+	sigStop = syscall.Signal(-1)
+	sigCont = syscall.Signal(-2)
+	sigTerm = syscall.Signal(-3)
+)
+
+func procAttr() *syscall.SysProcAttr { return nil }
+
+func signalProc(p *os.Process, sig syscall.Signal) error {
+	switch sig {
+	case sigTerm:
+		return p.Kill()
+	case sigCont:
+		return nil
+	}
+	return errUnsupported
+}
+
+func killProc(p *os.Process) error { return p.Kill() }
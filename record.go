@@ -0,0 +1,686 @@
+package main
+
+// Session recording and replay.
+//
+// --record FILE wraps the active source and appends every state change to a
+// JSONL file: one "snapshot" line with the full fleet, then one "update" line
+// per AgentUpdate, each stamped with the wall-clock time it was seen.
+//
+// --replay FILE plays such a file back as an ordinary AgentSource. State at
+// any position is rebuilt from the snapshot plus every update up to that
+// point, so seeking is deterministic. Timestamps inside agents are shifted by
+// the playback offset so uptimes and "ago" labels read as they did live.
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// recordingVersion is written on the snapshot line. Replay only loads
+// recordings of this version.
+const recordingVersion = 2
+
+// recordEvent is one line of a recording.
+type recordEvent struct {
+	Version int           `json:"v,omitempty"` // set on the snapshot line
+	Time    time.Time     `json:"t"`
+	Kind    string        `json:"kind"` // "snapshot" | "update"
+	Agents  []recordAgent `json:"agents,omitempty"`
+	Removed []string      `json:"removed,omitempty"`
+}
+
+// recordAgent is an agent as stored in a recording. Like jsonAgent it is
+// decoupled from Agent, so changes there don't break old recordings, but it
+// keeps everything replay needs to rebuild the agent.
+type recordAgent struct {
+	ID           string             `json:"id"`
+	Name         string             `json:"name"`
+	Model        string             `json:"model"`
+	Status       string             `json:"status"`
+	Phase        string             `json:"phase"`
+	Progress     int                `json:"progress"`
+	Task         string             `json:"task,omitempty"`
+	StartedAt    time.Time          `json:"started_at"`
+	LastActivity string             `json:"last_activity,omitempty"`
+	LastActiveAt time.Time          `json:"last_active_at"`
+	CurrentTool  string             `json:"current_tool,omitempty"`
+	ToolsUsed    int                `json:"tools_used,omitempty"`
+	TokensPerSec float64            `json:"tokens_per_sec,omitempty"`
+	TokensIn     int                `json:"tokens_in,omitempty"`
+	TokensOut    int                `json:"tokens_out,omitempty"`
+	TokensCached int                `json:"tokens_cached,omitempty"`
+	ISC          []jsonISCCriterion `json:"isc,omitempty"`
+	Events       []string           `json:"events,omitempty"`
+	WorkDir      string             `json:"work_dir,omitempty"`
+	PID          int                `json:"pid,omitempty"`
+	ExitCode     int                `json:"exit_code,omitempty"`
+	ParentID     string             `json:"parent_id,omitempty"`
+	Retries      int                `json:"retries,omitempty"`
+	Errors       []jsonError        `json:"errors,omitempty"`
+	Question     *jsonQuestion      `json:"question,omitempty"`
+	Phases       []jsonPhase        `json:"phases,omitempty"`
+}
+
+func toRecordAgent(a Agent) recordAgent {
+	r := recordAgent{
+		ID:           a.ID,
+		Name:         a.Name,
+		Model:        a.Model,
+		Status:       strings.ToLower(a.Status.String()),
+		Phase:        strings.ToLower(a.Phase.String()),
+		Progress:     a.Progress,
+		Task:         a.TaskDesc,
+		StartedAt:    a.StartedAt,
+		LastActivity: a.LastActivity,
+		LastActiveAt: a.LastActTime,
+		CurrentTool:  a.CurrentTool,
+		ToolsUsed:    a.ToolsUsed,
+		TokensPerSec: a.TokensPerSec,
+		TokensIn:     a.TotalTokensIn,
+		TokensOut:    a.TotalTokensOut,
+		TokensCached: a.TotalTokensCached,
+		Events:       a.EventLog,
+		WorkDir:      a.WorkDir,
+		PID:          a.PID,
+		ExitCode:     a.ExitCode,
+		ParentID:     a.ParentID,
+		Retries:      a.Retries,
+	}
+	for _, c := range a.ISCItems {
+		jc := jsonISCCriterion{Text: c.Text, Passed: c.Passed, Evidence: c.Evidence}
+		if !c.VerifiedAt.IsZero() {
+			t := c.VerifiedAt
+			jc.VerifiedAt = &t
+		}
+		r.ISC = append(r.ISC, jc)
+	}
+	for _, e := range a.Errors {
+		r.Errors = append(r.Errors, jsonError{
+			Message: e.Message, Tool: e.Tool, ExitCode: e.ExitCode,
+			Stderr: e.Stderr, Time: e.Time, Retries: e.Retries,
+		})
+	}
+	if q := a.Question; q.Text != "" {
+		r.Question = &jsonQuestion{Text: q.Text, Options: q.Options, AskedAt: q.Asked}
+	}
+	r.Phases = toJSONPhases(a.Phases)
+	return r
+}
+
+// agent rebuilds the Agent a record was made from.
+func (r recordAgent) agent() (Agent, error) {
+	st, ok := parseStatus(r.Status)
+	if !ok {
+		return Agent{}, fmt.Errorf("agent %s: unknown status %q", r.ID, r.Status)
+	}
+	ph, ok := parsePhase(r.Phase)
+	if !ok {
+		return Agent{}, fmt.Errorf("agent %s: unknown phase %q", r.ID, r.Phase)
+	}
+	a := Agent{
+		ID:                r.ID,
+		Name:              r.Name,
+		Model:             r.Model,
+		Status:            st,
+		Phase:             ph,
+		Progress:          r.Progress,
+		TaskDesc:          r.Task,
+		StartedAt:         r.StartedAt,
+		LastActivity:      r.LastActivity,
+		LastActTime:       r.LastActiveAt,
+		CurrentTool:       r.CurrentTool,
+		ToolsUsed:         r.ToolsUsed,
+		TokensPerSec:      r.TokensPerSec,
+		TotalTokensIn:     r.TokensIn,
+		TotalTokensOut:    r.TokensOut,
+		TotalTokensCached: r.TokensCached,
+		EventLog:          r.Events,
+		WorkDir:           r.WorkDir,
+		PID:               r.PID,
+		ExitCode:          r.ExitCode,
+		ParentID:          r.ParentID,
+		Retries:           r.Retries,
+	}
+	for _, jc := range r.ISC {
+		c := ISCCriterion{Text: jc.Text, Passed: jc.Passed, Evidence: jc.Evidence}
+		if jc.VerifiedAt != nil {
+			c.VerifiedAt = *jc.VerifiedAt
+		}
+		a.ISCItems = append(a.ISCItems, c)
+	}
+	for _, e := range r.Errors {
+		a.Errors = append(a.Errors, AgentError{
+			Message: e.Message, Tool: e.Tool, ExitCode: e.ExitCode,
+			Stderr: e.Stderr, Time: e.Time, Retries: e.Retries,
+		})
+	}
+	if q := r.Question; q != nil {
+		a.Question = AgentQuestion{Text: q.Text, Options: q.Options, Asked: q.AskedAt}
+	}
+	for _, jp := range r.Phases {
+		p, ok := parsePhase(jp.Phase)
+		if !ok {
+			return Agent{}, fmt.Errorf("agent %s: unknown phase %q", r.ID, jp.Phase)
+		}
+		s := PhaseSpan{Phase: p, Start: jp.Start, Tokens: jp.Tokens, Partial: jp.Partial}
+		if jp.End != nil {
+			s.End = *jp.End
+		}
+		a.Phases = append(a.Phases, s)
+	}
+	return a, nil
+}
+
+func toRecordAgents(agents []Agent) []recordAgent {
+	out := make([]recordAgent, len(agents))
+	for i, a := range agents {
+		out[i] = toRecordAgent(a)
+	}
+	return out
+}
+
+func parseStatus(s string) (AgentStatus, bool) {
+	for st := StatusRunning; st <= StatusStopped; st++ {
+		if strings.EqualFold(s, st.String()) {
+			return st, true
+		}
+	}
+	return 0, false
+}
+
+// ---------------------------------------------------------------------------
+// Recording
+// ---------------------------------------------------------------------------
+
+// recordSource passes another source through unchanged while writing it to disk.
+type recordSource struct {
+	AgentSource // Snapshot, Refresh, Control pass straight through
+
+	f   *os.File
+	w   *bufio.Writer
+	enc *json.Encoder
+
+	mu       sync.Mutex
+	err      error // first write error; recording stops after it
+	reported bool
+
+	updates  chan AgentUpdate
+	done     chan struct{}
+	finished chan struct{} // closed when run returns
+}
+
+func newRecordSource(inner AgentSource, path string) (*recordSource, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("record: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	return &recordSource{
+		AgentSource: inner,
+		f:           f,
+		w:           w,
+		enc:         json.NewEncoder(w),
+		updates:     make(chan AgentUpdate, 16),
+		done:        make(chan struct{}),
+		finished:    make(chan struct{}),
+	}, nil
+}
+
+func (s *recordSource) Start() {
+	s.write(recordEvent{Version: recordingVersion, Time: time.Now(), Kind: "snapshot", Agents: toRecordAgents(s.AgentSource.Snapshot())})
+	s.AgentSource.Start()
+	go s.run()
+}
+
+// Stop stops the inner source and waits for the last update to be written
+// before closing the file.
+func (s *recordSource) Stop() {
+	select {
+	case <-s.done:
+		return
+	default:
+		close(s.done)
+	}
+	s.AgentSource.Stop()
+	<-s.finished
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.w.Flush(); err != nil && s.err == nil {
+		s.err = err
+	}
+	if err := s.f.Close(); err != nil && s.err == nil {
+		s.err = err
+	}
+}
+
+// takeErr returns the write error the first time it is asked, for the
+// status bar.
+func (s *recordSource) takeErr() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.reported || s.err == nil {
+		return nil
+	}
+	s.reported = true
+	return fmt.Errorf("record: %w", s.err)
+}
+
+func (s *recordSource) Updates() <-chan AgentUpdate { return s.updates }
+
+// Unwrap returns the source being recorded.
+func (s *recordSource) Unwrap() AgentSource { return s.AgentSource }
+
+// Spawn forwards to the inner source so recording never hides the spawn dialog.
+func (s *recordSource) Spawn(req SpawnRequest) (Agent, error) {
+	sp, ok := s.AgentSource.(Spawner)
+	if !ok {
+		return Agent{}, errUnsupported
+	}
+	return sp.Spawn(req)
+}
+
+// Answer forwards to the inner source, so answers are delivered while
+// recording.
+func (s *recordSource) Answer(id, answer string) error {
+	an, ok := s.AgentSource.(Answerer)
+	if !ok {
+		return errUnsupported
+	}
+	return an.Answer(id, answer)
+}
+
+// CanRestart forwards to the inner source, so restart policies still apply
+// while recording.
+func (s *recordSource) CanRestart(id string) bool {
+	rs, ok := s.AgentSource.(Restarter)
+	return ok && rs.CanRestart(id)
+}
+
+func (s *recordSource) run() {
+	defer close(s.finished)
+	defer close(s.updates)
+	for u := range s.AgentSource.Updates() {
+		s.write(recordEvent{Time: time.Now(), Kind: "update", Agents: toRecordAgents(u.Agents), Removed: u.Removed})
+		select {
+		case s.updates <- u:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// write appends one event and flushes so a crash loses at most one line.
+func (s *recordSource) write(ev recordEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return
+	}
+	if err := s.enc.Encode(ev); err != nil {
+		s.err = err
+		return
+	}
+	s.err = s.w.Flush()
+}
+
+// ---------------------------------------------------------------------------
+// Replay
+// ---------------------------------------------------------------------------
+
+// Player is implemented by sources that play back recorded time.
+type Player interface {
+	Playback() PlaybackState
+	SetPaused(paused bool)
+	SetSpeed(speed float64)
+	Seek(to time.Duration)
+}
+
+// playerOf finds the Player behind src, looking through decorators such as
+// --record and --agent-cmd that wrap it.
+func playerOf(src AgentSource) (Player, bool) {
+	for src != nil {
+		if pl, ok := src.(Player); ok {
+			return pl, true
+		}
+		w, ok := src.(interface{ Unwrap() AgentSource })
+		if !ok {
+			break
+		}
+		src = w.Unwrap()
+	}
+	return nil, false
+}
+
+// PlaybackState describes where a Player is.
+type PlaybackState struct {
+	Position time.Duration
+	Length   time.Duration
+	Speed    float64
+	Paused   bool
+}
+
+// replaySpeeds are the steps the speed keys move through.
+var replaySpeeds = []float64{0.5, 1, 2, 4, 8, 16}
+
+const replayFrame = 100 * time.Millisecond
+
+// replayEvent is a recordEvent with its agents rebuilt.
+type replayEvent struct {
+	Time    time.Time
+	Agents  []Agent
+	Removed []string
+}
+
+// replaySource plays a recording back through the normal UI.
+type replaySource struct {
+	events []replayEvent // events[0] is the snapshot
+	origin time.Time     // time of the snapshot
+
+	mu        sync.Mutex
+	pos       time.Duration
+	next      int // index of the first event not yet applied
+	speed     float64
+	paused    bool
+	state     []Agent // fleet at pos, in display order
+	published map[string]bool
+	seeked    bool // the next frame must publish the full state
+
+	updates chan AgentUpdate
+	done    chan struct{}
+}
+
+// loadReplay reads and validates a recording.
+func loadReplay(path string) (*replaySource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	defer f.Close()
+
+	var events []replayEvent
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		// Agents are decoded once the version is known
+		var ev struct {
+			recordEvent
+			Agents json.RawMessage `json:"agents,omitempty"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			return nil, fmt.Errorf("replay: %s:%d: %w", path, line, err)
+		}
+		if len(events) == 0 {
+			if ev.Kind != "snapshot" {
+				return nil, errors.New("replay: recording must start with a snapshot")
+			}
+			if ev.Version != recordingVersion {
+				return nil, fmt.Errorf("replay: recording version %d is not supported (want %d)", ev.Version, recordingVersion)
+			}
+		}
+		agents, err := decodeRecordAgents(ev.Agents)
+		if err != nil {
+			return nil, fmt.Errorf("replay: %s:%d: %w", path, line, err)
+		}
+		events = append(events, replayEvent{Time: ev.Time, Agents: agents, Removed: ev.Removed})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, errors.New("replay: recording must start with a snapshot")
+	}
+	// Tolerate clock hiccups: playback needs non-decreasing times.
+	sort.SliceStable(events[1:], func(i, j int) bool { return events[1+i].Time.Before(events[1+j].Time) })
+
+	s := &replaySource{
+		events:    events,
+		origin:    events[0].Time,
+		speed:     1,
+		published: map[string]bool{},
+		updates:   make(chan AgentUpdate, 16),
+		done:      make(chan struct{}),
+	}
+	s.rebuild(0)
+	return s, nil
+}
+
+// decodeRecordAgents decodes the agents of one line.
+func decodeRecordAgents(raw json.RawMessage) ([]Agent, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var recs []recordAgent
+	if err := json.Unmarshal(raw, &recs); err != nil {
+		return nil, err
+	}
+	var agents []Agent
+	for _, r := range recs {
+		a, err := r.agent()
+		if err != nil {
+			return nil, err
+		}
+		agents = append(agents, a)
+	}
+	return agents, nil
+}
+
+func (s *replaySource) Start() { go s.run() }
+
+func (s *replaySource) Stop() {
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+}
+
+func (s *replaySource) Snapshot() []Agent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := s.rebased(s.state)
+	for _, a := range out {
+		s.published[a.ID] = true
+	}
+	return out
+}
+
+func (s *replaySource) Updates() <-chan AgentUpdate { return s.updates }
+
+func (s *replaySource) Refresh() {}
+
+func (s *replaySource) Control(string, ControlAction) error {
+	return errors.New("recordings are read-only")
+}
+
+func (s *replaySource) Playback() PlaybackState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return PlaybackState{Position: s.pos, Length: s.length(), Speed: s.speed, Paused: s.paused}
+}
+
+func (s *replaySource) SetPaused(paused bool) {
+	s.mu.Lock()
+	s.paused = paused
+	s.mu.Unlock()
+}
+
+func (s *replaySource) SetSpeed(speed float64) {
+	s.mu.Lock()
+	s.speed = max(replaySpeeds[0], min(speed, replaySpeeds[len(replaySpeeds)-1]))
+	s.mu.Unlock()
+}
+
+func (s *replaySource) Seek(to time.Duration) {
+	s.mu.Lock()
+	s.rebuild(max(0, min(to, s.length())))
+	s.seeked = true
+	s.mu.Unlock()
+}
+
+func (s *replaySource) length() time.Duration {
+	return s.events[len(s.events)-1].Time.Sub(s.origin)
+}
+
+// rebuild recomputes state at pos from scratch. Callers hold s.mu.
+func (s *replaySource) rebuild(pos time.Duration) {
+	s.state = nil
+	for _, a := range s.events[0].Agents {
+		s.state = append(s.state, copyAgent(a))
+	}
+	s.pos, s.next = pos, 1
+	s.advance()
+}
+
+// advance applies every event at or before pos and returns the IDs touched.
+// Callers hold s.mu.
+func (s *replaySource) advance() (changed []string, removed []string) {
+	for s.next < len(s.events) && s.events[s.next].Time.Sub(s.origin) <= s.pos {
+		ev := s.events[s.next]
+		s.next++
+		idx := make(map[string]int, len(s.state))
+		for i, a := range s.state {
+			idx[a.ID] = i
+		}
+		for _, a := range ev.Agents {
+			if i, ok := idx[a.ID]; ok {
+				s.state[i] = copyAgent(a)
+			} else {
+				s.state = append(s.state, copyAgent(a))
+			}
+			changed = append(changed, a.ID)
+		}
+		if len(ev.Removed) > 0 {
+			gone := map[string]bool{}
+			for _, id := range ev.Removed {
+				gone[id] = true
+			}
+			kept := s.state[:0]
+			for _, a := range s.state {
+				if !gone[a.ID] {
+					kept = append(kept, a)
+				}
+			}
+			s.state = kept
+			removed = append(removed, ev.Removed...)
+		}
+	}
+	return changed, removed
+}
+
+// rebased copies agents with their timestamps shifted from recording time to
+// the wall clock at the current playback position. Callers hold s.mu.
+func (s *replaySource) rebased(agents []Agent) []Agent {
+	shift := time.Since(s.origin.Add(s.pos))
+	out := make([]Agent, len(agents))
+	for i, a := range agents {
+		a = copyAgent(a)
+		a.StartedAt = a.StartedAt.Add(shift)
+		a.LastActTime = a.LastActTime.Add(shift)
+		for j := range a.ISCItems {
+			if !a.ISCItems[j].VerifiedAt.IsZero() {
+				a.ISCItems[j].VerifiedAt = a.ISCItems[j].VerifiedAt.Add(shift)
+			}
+		}
+		for j := range a.Errors {
+			a.Errors[j].Time = a.Errors[j].Time.Add(shift)
+		}
+		if !a.Question.Asked.IsZero() {
+			a.Question.Asked = a.Question.Asked.Add(shift)
+		}
+		for j := range a.Phases {
+			a.Phases[j].Start = a.Phases[j].Start.Add(shift)
+			if !a.Phases[j].End.IsZero() {
+				a.Phases[j].End = a.Phases[j].End.Add(shift)
+			}
+		}
+		out[i] = a
+	}
+	return out
+}
+
+func (s *replaySource) run() {
+	defer close(s.updates)
+	t := time.NewTicker(replayFrame)
+	defer t.Stop()
+	last := time.Now()
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-t.C:
+			elapsed := now.Sub(last)
+			last = now
+			u, ok := s.frame(elapsed)
+			if !ok {
+				continue
+			}
+			select {
+			case s.updates <- u:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
+
+// frame advances playback by one wall-clock step and builds the update to
+// publish. Frames are published while playing even if nothing changed so
+// the timeline keeps moving.
+func (s *replaySource) frame(elapsed time.Duration) (AgentUpdate, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seeked {
+		s.seeked = false
+		u := AgentUpdate{Agents: s.rebased(s.state)}
+		present := map[string]bool{}
+		for _, a := range s.state {
+			present[a.ID] = true
+		}
+		for id := range s.published {
+			if !present[id] {
+				u.Removed = append(u.Removed, id)
+			}
+		}
+		s.published = present
+		return u, true
+	}
+	if s.paused || s.pos >= s.length() {
+		return AgentUpdate{}, false
+	}
+
+	s.pos = min(s.pos+time.Duration(float64(elapsed)*s.speed), s.length())
+	changed, removed := s.advance()
+	u := AgentUpdate{Removed: removed}
+	if len(changed) > 0 {
+		want := map[string]bool{}
+		for _, id := range changed {
+			want[id] = true
+		}
+		var touched []Agent
+		for _, a := range s.state {
+			if want[a.ID] {
+				touched = append(touched, a)
+				s.published[a.ID] = true
+			}
+		}
+		u.Agents = s.rebased(touched)
+	}
+	for _, id := range removed {
+		delete(s.published, id)
+	}
+	return u, true
+}
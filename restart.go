@@ -0,0 +1,264 @@
+package main
+
+// Restart policies — bring failed agents back on their own.
+//
+// [restart] in the config file maps agent names to a policy; "*" covers
+// every name without an entry of its own, and unset keys fall back to "*"
+// and then to the defaults below:
+//
+//	[restart]
+//	"*"      = { policy = "on-failure", max_retries = 3 }
+//	Engineer = { policy = "always", backoff = "10s" }
+//	Intern   = { policy = "never" }
+//
+// Restarting is opt-in: an agent with no entry is left alone, as under
+// never. on-failure starts an agent again when it enters Error, up to
+// max_retries times in a row; always also restarts agents that finished
+// cleanly, with no retry limit. Each restart waits backoff, doubled
+// per retry up to max_backoff, give or take 20% jitter. A circuit breaker
+// stops restarting an agent that has failed breaker_failures times within
+// breaker_window; it stays in Error until it is started by hand.
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	policyNever     = "never"
+	policyOnFailure = "on-failure"
+	policyAlways    = "always"
+)
+
+// restartPolicy is the policy an agent is supervised under.
+type restartPolicy struct {
+	Policy          string
+	MaxRetries      int
+	Backoff         duration
+	MaxBackoff      duration
+	BreakerFailures int
+	BreakerWindow   duration
+}
+
+// restartEntry is one [restart] entry. Unset keys are inherited; the counts
+// are pointers since an explicit 0 is a setting of its own.
+type restartEntry struct {
+	Policy          string   `toml:"policy"`
+	MaxRetries      *int     `toml:"max_retries"`
+	Backoff         duration `toml:"backoff"`
+	MaxBackoff      duration `toml:"max_backoff"`
+	BreakerFailures *int     `toml:"breaker_failures"`
+	BreakerWindow   duration `toml:"breaker_window"`
+}
+
+var defaultRestart = restartPolicy{
+	Policy:          policyNever,
+	MaxRetries:      3,
+	Backoff:         duration(5 * time.Second),
+	MaxBackoff:      duration(5 * time.Minute),
+	BreakerFailures: 5,
+	BreakerWindow:   duration(10 * time.Minute),
+}
+
+// Restarter is implemented by sources that can start an agent again after
+// it failed or finished. Restart policies only apply where CanRestart holds.
+type Restarter interface {
+	CanRestart(id string) bool
+}
+
+// restartFor resolves the policy for an agent name, key by key: its own
+// entry, then "*", then the defaults.
+func restartFor(table map[string]restartEntry, name string) restartPolicy {
+	p := defaultRestart
+	for _, k := range []string{"*", name} {
+		o, ok := table[k]
+		if !ok {
+			continue
+		}
+		if o.Policy != "" {
+			p.Policy = o.Policy
+		}
+		if o.MaxRetries != nil {
+			p.MaxRetries = *o.MaxRetries
+		}
+		if o.Backoff != 0 {
+			p.Backoff = o.Backoff
+		}
+		if o.MaxBackoff != 0 {
+			p.MaxBackoff = o.MaxBackoff
+		}
+		if o.BreakerFailures != nil {
+			p.BreakerFailures = *o.BreakerFailures
+		}
+		if o.BreakerWindow != 0 {
+			p.BreakerWindow = o.BreakerWindow
+		}
+	}
+	return p
+}
+
+// checkRestart validates a [restart] table.
+func checkRestart(table map[string]restartEntry) error {
+	for _, name := range sortedKeys(table) {
+		p := table[name]
+		switch p.Policy {
+		case "", policyNever, policyOnFailure, policyAlways:
+		default:
+			return fmt.Errorf("restart.%s.policy %q: want %s, %s or %s",
+				name, p.Policy, policyNever, policyOnFailure, policyAlways)
+		}
+		if n := p.MaxRetries; n != nil && (*n < 0 || *n > 100) {
+			return fmt.Errorf("restart.%s.max_retries %d: must be between 0 and 100", name, *n)
+		}
+		if n := p.BreakerFailures; n != nil && (*n < 1 || *n > 1000) {
+			return fmt.Errorf("restart.%s.breaker_failures %d: must be between 1 and 1000", name, *n)
+		}
+		if p.Backoff < 0 || p.MaxBackoff < 0 || p.BreakerWindow < 0 {
+			return fmt.Errorf("restart.%s: durations must not be negative", name)
+		}
+		if r := restartFor(table, name); r.MaxBackoff < r.Backoff {
+			return fmt.Errorf("restart.%s: max_backoff %s is less than backoff %s",
+				name, time.Duration(r.MaxBackoff), time.Duration(r.Backoff))
+		}
+	}
+	return nil
+}
+
+// delay is how long to wait before the restart that follows retries
+// earlier ones: backoff doubled per retry, capped, with ±20% jitter.
+func (p restartPolicy) delay(retries int) time.Duration {
+	d, ceil := time.Duration(p.Backoff), time.Duration(p.MaxBackoff)
+	for i := 0; i < retries && d < ceil; i++ {
+		d *= 2
+	}
+	d = time.Duration(float64(min(d, ceil)) * (0.8 + 0.4*rand.Float64()))
+	return min(d, ceil)
+}
+
+// pendingRestart is a restart waiting for its backoff to pass.
+type pendingRestart struct {
+	at    time.Time
+	retry int // 1-based retry number; 0 restarts an agent that finished
+	limit int // max_retries under on-failure, 0 when unlimited
+}
+
+// restartMsg fires when a scheduled restart is due.
+type restartMsg struct {
+	id string
+	at time.Time
+}
+
+// supervisor applies restart policies. It is owned by the model, like the
+// notifier: observe schedules restarts as agents fail or finish, flush
+// hands the timers to Bubble Tea, and due confirms that one that fired
+// still applies.
+type supervisor struct {
+	pending  map[string]pendingRestart
+	failures map[string][]time.Time // times each agent entered Error, within its breaker window
+	halted   map[string]string      // why a failed agent is not being restarted
+	timers   []tea.Cmd
+}
+
+func newSupervisor() *supervisor {
+	return &supervisor{
+		pending:  map[string]pendingRestart{},
+		failures: map[string][]time.Time{},
+		halted:   map[string]string{},
+	}
+}
+
+// observe compares two versions of an agent and schedules a restart if its
+// policy calls for one. Any status change cancels a restart still pending.
+func (s *supervisor) observe(prev, next Agent, p restartPolicy, now time.Time) {
+	if next.Status != prev.Status {
+		delete(s.pending, next.ID)
+		delete(s.halted, next.ID)
+	}
+	failed := next.Status == StatusError && prev.Status != StatusError
+	finished := next.Status == StatusIdle && next.Phase == PhaseDone &&
+		(prev.Status != StatusIdle || prev.Phase != PhaseDone)
+
+	if failed {
+		recent := s.failures[next.ID][:0]
+		for _, t := range s.failures[next.ID] {
+			if now.Sub(t) < time.Duration(p.BreakerWindow) {
+				recent = append(recent, t)
+			}
+		}
+		s.failures[next.ID] = append(recent, now)
+	}
+
+	switch {
+	case p.Policy == policyNever:
+	case failed && len(s.failures[next.ID]) >= p.BreakerFailures:
+		s.halted[next.ID] = fmt.Sprintf("breaker open: %d failures in %s",
+			len(s.failures[next.ID]), time.Duration(p.BreakerWindow))
+	case failed && p.Policy == policyOnFailure && next.Retries >= p.MaxRetries:
+		s.halted[next.ID] = fmt.Sprintf("gave up after %d retries", next.Retries)
+	case failed:
+		limit := p.MaxRetries
+		if p.Policy == policyAlways {
+			limit = 0
+		}
+		s.schedule(next.ID, pendingRestart{now.Add(p.delay(next.Retries)), next.Retries + 1, limit})
+	case finished && p.Policy == policyAlways:
+		s.schedule(next.ID, pendingRestart{now.Add(p.delay(0)), 0, 0})
+	}
+}
+
+func (s *supervisor) schedule(id string, pr pendingRestart) {
+	s.pending[id] = pr
+	s.timers = append(s.timers, tea.Tick(time.Until(pr.at), func(time.Time) tea.Msg {
+		return restartMsg{id, pr.at}
+	}))
+}
+
+// flush returns the timers scheduled since the last call as one command.
+func (s *supervisor) flush() tea.Cmd {
+	cmds := s.timers
+	s.timers = nil
+	return tea.Batch(cmds...)
+}
+
+// due reports whether the restart behind msg is still wanted, and clears it.
+func (s *supervisor) due(msg restartMsg) bool {
+	pr, ok := s.pending[msg.id]
+	if !ok || !pr.at.Equal(msg.at) {
+		return false
+	}
+	delete(s.pending, msg.id)
+	return true
+}
+
+// forget drops an agent that left the fleet.
+func (s *supervisor) forget(id string) {
+	delete(s.pending, id)
+	delete(s.failures, id)
+	delete(s.halted, id)
+}
+
+// countdown describes a pending restart, e.g. "↻ retry 2/3 in 12s".
+func (s *supervisor) countdown(id string, now time.Time) (string, bool) {
+	pr, ok := s.pending[id]
+	if !ok {
+		return "", false
+	}
+	in := fmtDuration(max(pr.at.Sub(now), 0).Round(time.Second))
+	switch {
+	case pr.retry == 0:
+		return "↻ restart in " + in, true
+	case pr.limit > 0:
+		return fmt.Sprintf("↻ retry %d/%d in %s", pr.retry, pr.limit, in), true
+	}
+	return fmt.Sprintf("↻ retry %d in %s", pr.retry, in), true
+}
+
+// haltedBy says why a failed agent is not being restarted, if its policy
+// would otherwise have done so.
+func (s *supervisor) haltedBy(id string) (string, bool) {
+	why, ok := s.halted[id]
+	return why, ok
+}
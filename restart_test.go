@@ -0,0 +1,117 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRestartDelay(t *testing.T) {
+	p := restartPolicy{Backoff: duration(5 * time.Second), MaxBackoff: duration(5 * time.Minute)}
+	tests := []struct {
+		name     string
+		policy   restartPolicy
+		retries  int
+		min, max time.Duration
+	}{
+		{"first", p, 0, 4 * time.Second, 6 * time.Second},
+		{"doubled", p, 1, 8 * time.Second, 12 * time.Second},
+		{"doubled three times", p, 3, 32 * time.Second, 48 * time.Second},
+		{"jitter never passes the cap", p, 6, 240 * time.Second, 300 * time.Second}, // 320s capped
+		{"far past the cap", p, 1000, 240 * time.Second, 300 * time.Second},
+		{"backoff at the cap", restartPolicy{Backoff: duration(time.Minute), MaxBackoff: duration(time.Minute)}, 0,
+			48 * time.Second, time.Minute},
+		{"no backoff", restartPolicy{MaxBackoff: duration(time.Minute)}, 5, 0, 0},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 200; i++ {
+			if d := tt.policy.delay(tt.retries); d < tt.min || d > tt.max {
+				t.Errorf("%s: delay %v, want %v to %v", tt.name, d, tt.min, tt.max)
+				break
+			}
+		}
+	}
+}
+
+func TestSupervisorObserve(t *testing.T) {
+	t0 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	onFailure := restartFor(map[string]restartEntry{"*": {Policy: policyOnFailure}}, "Engineer")
+	with := func(p restartPolicy, change func(p *restartPolicy)) restartPolicy {
+		change(&p)
+		return p
+	}
+
+	// step moves the agent to a status at an offset from t0; an Idle agent
+	// has finished
+	type step struct {
+		at      time.Duration
+		to      AgentStatus
+		retries int
+	}
+	tests := []struct {
+		name      string
+		policy    restartPolicy
+		steps     []step
+		wantRetry int // retry number of the pending restart, -1 for none
+		wantLimit int
+		wantHalt  string
+	}{
+		{"never", defaultRestart, []step{{0, StatusError, 0}}, -1, 0, ""},
+		{"first failure", onFailure, []step{{0, StatusError, 0}}, 1, 3, ""},
+		{"third failure", onFailure, []step{{0, StatusError, 2}}, 3, 3, ""},
+		{"out of retries", onFailure, []step{{0, StatusError, 3}}, -1, 0, "gave up after 3 retries"},
+		{"max_retries = 0", with(onFailure, func(p *restartPolicy) { p.MaxRetries = 0 }),
+			[]step{{0, StatusError, 0}}, -1, 0, "gave up after 0 retries"},
+		{"always has no retry limit", with(onFailure, func(p *restartPolicy) { p.Policy = policyAlways }),
+			[]step{{0, StatusError, 10}}, 11, 0, ""},
+		{"always restarts a finished agent", with(onFailure, func(p *restartPolicy) { p.Policy = policyAlways }),
+			[]step{{0, StatusIdle, 0}}, 0, 0, ""},
+		{"on-failure leaves a finished agent", onFailure, []step{{0, StatusIdle, 0}}, -1, 0, ""},
+		{"a status change cancels the restart", onFailure, []step{{0, StatusError, 0}, {time.Second, StatusRunning, 1}}, -1, 0, ""},
+
+		{"breaker opens", with(onFailure, func(p *restartPolicy) { p.BreakerFailures, p.MaxRetries = 3, 100 }),
+			[]step{{0, StatusError, 0}, {time.Minute, StatusRunning, 1}, {2 * time.Minute, StatusError, 1},
+				{3 * time.Minute, StatusRunning, 2}, {4 * time.Minute, StatusError, 2}},
+			-1, 0, "breaker open: 3 failures in 10m0s"},
+		{"failures leave the breaker window", with(onFailure, func(p *restartPolicy) { p.BreakerFailures, p.MaxRetries = 3, 100 }),
+			[]step{{0, StatusError, 0}, {time.Minute, StatusRunning, 1}, {6 * time.Minute, StatusError, 1},
+				{7 * time.Minute, StatusRunning, 2}, {10 * time.Minute, StatusError, 2}},
+			3, 100, ""},
+		{"the breaker wins over retries left", with(onFailure, func(p *restartPolicy) { p.BreakerFailures = 1 }),
+			[]step{{0, StatusError, 0}}, -1, 0, "breaker open: 1 failures in 10m0s"},
+	}
+	for _, tt := range tests {
+		s := newSupervisor()
+		prev := Agent{ID: "pai-1a2b", Name: "Engineer", Status: StatusRunning, Phase: PhaseBuild}
+		var last time.Time
+		for _, st := range tt.steps {
+			next := prev
+			next.Status, next.Retries = st.to, st.retries
+			if st.to == StatusIdle {
+				next.Phase = PhaseDone
+			}
+			last = t0.Add(st.at)
+			s.observe(prev, next, tt.policy, last)
+			prev = next
+		}
+
+		pr, ok := s.pending[prev.ID]
+		switch {
+		case tt.wantRetry < 0 && ok:
+			t.Errorf("%s: restart %+v pending, want none", tt.name, pr)
+		case tt.wantRetry >= 0 && !ok:
+			t.Errorf("%s: no restart pending", tt.name)
+		case ok && (pr.retry != tt.wantRetry || pr.limit != tt.wantLimit):
+			t.Errorf("%s: retry %d of %d, want %d of %d", tt.name, pr.retry, pr.limit, tt.wantRetry, tt.wantLimit)
+		case ok:
+			if d := pr.at.Sub(last); d <= 0 || d > time.Duration(tt.policy.MaxBackoff) {
+				t.Errorf("%s: restart in %v", tt.name, d)
+			}
+		}
+		if got, _ := s.haltedBy(prev.ID); got != tt.wantHalt {
+			t.Errorf("%s: halted %q, want %q", tt.name, got, tt.wantHalt)
+		}
+		if ok && len(s.timers) == 0 {
+			t.Errorf("%s: restart scheduled without a timer", tt.name)
+		}
+	}
+}
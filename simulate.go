@@ -0,0 +1,441 @@
+package main
+
+// Simulated agent source — randomized agents for demos and screenshots.
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const simTickInterval = 2 * time.Second
+
+// SimulatedSource generates plausible agent activity on a fixed tick.
+type SimulatedSource struct {
+	mu     sync.Mutex
+	agents []Agent
+
+	updates chan AgentUpdate
+	refresh chan bool // true → advance the simulation, false → just republish
+	done    chan struct{}
+}
+
+// newSimulatedSource seeds n random agents. Nothing moves until Start.
+func newSimulatedSource(n int) *SimulatedSource {
+	agents := make([]Agent, 0, n)
+	for i := 0; i < n; i++ {
+		agents = append(agents, makeAgent())
+	}
+	// Some of the fleet starts out as sub-agents of an earlier agent
+	for i := 3; i < n; i++ {
+		if rand.Float32() < 0.3 {
+			agents[i].ParentID = agents[rand.Intn(i)].ID
+		}
+	}
+	return &SimulatedSource{
+		agents:  agents,
+		updates: make(chan AgentUpdate, 16),
+		refresh: make(chan bool, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+func (s *SimulatedSource) Start() { go s.run() }
+
+func (s *SimulatedSource) Stop() {
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+}
+
+func (s *SimulatedSource) Snapshot() []Agent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Agent, len(s.agents))
+	for i, a := range s.agents {
+		out[i] = copyAgent(a)
+	}
+	return out
+}
+
+func (s *SimulatedSource) Updates() <-chan AgentUpdate { return s.updates }
+
+// Refresh runs an extra simulation step right away.
+func (s *SimulatedSource) Refresh() { s.poke(true) }
+
+func (s *SimulatedSource) Control(id string, action ControlAction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.find(id)
+	if a == nil {
+		return fmt.Errorf("agent %s not found", id)
+	}
+	prev := *a
+	switch action {
+	case ActionStart:
+		a.Status = StatusRunning
+		a.StartedAt = time.Now()
+		a.Phase = PhaseObserve
+		a.Progress = 0
+		trackRetry(a, prev.Status)
+	case ActionStop:
+		a.Status = StatusStopped
+		a.TokensPerSec = 0
+	case ActionPause:
+		a.Status = StatusPaused
+		a.TokensPerSec = 0
+	case ActionResume:
+		a.Status = StatusRunning
+	}
+	if action != ActionPause {
+		a.Question = AgentQuestion{}
+	}
+	trackPhase(a, prev, time.Now())
+	s.poke(false)
+	return nil
+}
+
+// Answer resumes an agent that is waiting on a question.
+func (s *SimulatedSource) Answer(id, answer string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.find(id)
+	if a == nil {
+		return fmt.Errorf("agent %s not found", id)
+	}
+	if !a.waiting() {
+		return errNotWaiting
+	}
+	now := time.Now()
+	a.Status = StatusRunning
+	a.Question = AgentQuestion{}
+	a.LastActivity = "answered: " + truncate(oneLine(answer), 60)
+	a.LastActTime = now
+	a.EventLog = appendEvent(a.EventLog, now, a.LastActivity)
+	s.poke(false)
+	return nil
+}
+
+// CanRestart holds for every simulated agent. Restarting is opt-in, so
+// without a policy failed agents still recover by themselves now and then.
+func (s *SimulatedSource) CanRestart(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.find(id) != nil
+}
+
+// Spawn adds a running agent for the request. The simulation picks it up
+// from the next tick like any other agent.
+func (s *SimulatedSource) Spawn(req SpawnRequest) (Agent, error) {
+	if err := req.Validate(); err != nil {
+		return Agent{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := newSpawnedAgent("pai-"+randHex4(), req, time.Now())
+	for s.find(a.ID) != nil {
+		a.ID = "pai-" + randHex4()
+	}
+	s.agents = append(s.agents, a)
+	s.poke(false)
+	return copyAgent(a), nil
+}
+
+// poke wakes the run loop. An advance request is never downgraded by a
+// pending republish.
+func (s *SimulatedSource) poke(advance bool) {
+	select {
+	case s.refresh <- advance:
+	default:
+		if advance {
+			select {
+			case <-s.refresh:
+			default:
+			}
+			select {
+			case s.refresh <- true:
+			default:
+			}
+		}
+	}
+}
+
+func (s *SimulatedSource) find(id string) *Agent {
+	for i := range s.agents {
+		if s.agents[i].ID == id {
+			return &s.agents[i]
+		}
+	}
+	return nil
+}
+
+func (s *SimulatedSource) run() {
+	t := time.NewTicker(simTickInterval)
+	defer t.Stop()
+	defer close(s.updates)
+	for {
+		advance := true
+		select {
+		case <-s.done:
+			return
+		case <-t.C:
+		case advance = <-s.refresh:
+		}
+
+		s.mu.Lock()
+		var removed []string
+		if advance {
+			removed = s.step()
+		}
+		u := AgentUpdate{Agents: make([]Agent, len(s.agents)), Removed: removed}
+		for i, a := range s.agents {
+			u.Agents[i] = copyAgent(a)
+		}
+		s.mu.Unlock()
+
+		select {
+		case s.updates <- u:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func makeAgent() Agent {
+	name := pickRand(agentNames)
+	model := pickRand(models)
+	now := time.Now()
+	status := AgentStatus(rand.Intn(3)) // Running, Idle, or Paused
+	phase := Phase(rand.Intn(7))
+
+	// ISC criteria with random pass/fail
+	iscCount := 3 + rand.Intn(4)
+	isc := make([]ISCCriterion, 0, iscCount)
+	for i := 0; i < iscCount; i++ {
+		isc = append(isc, ISCCriterion{
+			Text:   pickRand(iscPool),
+			Passed: rand.Float32() < 0.6,
+		})
+	}
+
+	// Seed event log
+	log := make([]string, 0, 8)
+	for i := 0; i < 4+rand.Intn(5); i++ {
+		t := now.Add(-time.Duration(rand.Intn(300)) * time.Second)
+		tool := pickRand(toolNames)
+		log = append(log, fmt.Sprintf("[%s] %s: %s", t.Format("15:04:05"), tool, pickRand(activities)))
+	}
+
+	// Token throughput based on model
+	tokRange := modelTokRanges[model]
+	tokps := tokRange[0] + rand.Float64()*(tokRange[1]-tokRange[0])
+
+	// Progress tied to phase
+	baseProgress := int(phase) * 14 // ~14% per phase
+	progress := clamp(baseProgress+rand.Intn(14), 0, 100)
+	var question AgentQuestion
+	if status == StatusPaused {
+		question = simQuestion(now.Add(-time.Duration(rand.Intn(120)) * time.Second))
+	}
+	if status == StatusIdle {
+		progress = 100
+		phase = PhaseDone
+	}
+	if status == StatusStopped {
+		progress = 0
+	}
+
+	a := Agent{
+		ID:             "pai-" + randHex4(),
+		Name:           name,
+		Status:         status,
+		StartedAt:      now.Add(-time.Duration(rand.Intn(600)) * time.Second),
+		LastActTime:    now.Add(-time.Duration(rand.Intn(20)) * time.Second),
+		LastActivity:   pickRand(activities),
+		Model:          model,
+		ISCItems:       isc,
+		EventLog:       log,
+		Phase:          phase,
+		Progress:       progress,
+		TokensPerSec:   tokps,
+		TotalTokensIn:  5000 + rand.Intn(50000),
+		TotalTokensOut: 1000 + rand.Intn(20000),
+		TaskDesc:       pickRand(taskDescs),
+		ToolsUsed:      rand.Intn(40),
+		CurrentTool:    pickRand(toolNames),
+		Question:       question,
+	}
+	beginPhases(&a, now, true)
+	return a
+}
+
+// simQuestion picks what an agent pausing for input asks.
+func simQuestion(now time.Time) AgentQuestion {
+	q := pickRand(questionPool)
+	q.Options = append([]string(nil), q.Options...)
+	q.Asked = now
+	return q
+}
+
+// simError picks the failure for an agent entering StatusError.
+func simError(now time.Time) AgentError {
+	e := pickRand(errorPool)
+	e.Time = now
+	return e
+}
+
+// step mutates agent state once per tick for real-time feel and returns the
+// IDs of any agents it garbage-collected.
+func (s *SimulatedSource) step() (removed []string) {
+	now := time.Now()
+	before := append([]Agent(nil), s.agents...)
+
+	// Transition 1-2 agent statuses
+	transitions := 1 + rand.Intn(2)
+	for t := 0; t < transitions && len(s.agents) > 0; t++ {
+		idx := rand.Intn(len(s.agents))
+		a := &s.agents[idx]
+		prev := a.Status
+		switch a.Status {
+		case StatusRunning:
+			if rand.Float32() < 0.15 {
+				a.Status = []AgentStatus{StatusIdle, StatusPaused, StatusError}[rand.Intn(3)]
+				if a.Status == StatusIdle {
+					a.Phase = PhaseDone
+					a.Progress = 100
+					a.TokensPerSec = 0
+				}
+				if a.Status == StatusError {
+					addError(a, simError(now))
+				}
+				if a.Status == StatusPaused {
+					a.Question = simQuestion(now)
+					a.TokensPerSec = 0
+					a.CurrentTool = "AskUserQuestion"
+					a.EventLog = appendEvent(a.EventLog, now, "awaiting input: "+a.Question.Text)
+				}
+			}
+		case StatusIdle:
+			if rand.Float32() < 0.3 {
+				a.Status = StatusRunning
+				a.Phase = PhaseObserve
+				a.Progress = 0
+				a.TaskDesc = pickRand(taskDescs)
+			}
+		case StatusPaused:
+			// A question is occasionally answered from the agent's own terminal
+			if a.waiting() && rand.Float32() < 0.1 {
+				a.Status = StatusRunning
+				a.Question = AgentQuestion{}
+				a.EventLog = appendEvent(a.EventLog, now, "answered in terminal")
+			} else if !a.waiting() && rand.Float32() < 0.4 {
+				a.Status = StatusRunning
+			}
+		case StatusError:
+			if rand.Float32() < 0.3 {
+				a.Status = StatusRunning
+				a.Phase = PhaseObserve
+				a.Progress = 0
+			}
+		}
+		trackRetry(a, prev)
+	}
+
+	// Update all running agents: advance phase, progress, tokens, activity
+	var spawned []Agent
+	for i := range s.agents {
+		a := &s.agents[i]
+		if a.Status != StatusRunning {
+			continue
+		}
+
+		// Advance phase probabilistically
+		if a.Phase < PhaseDone && rand.Float32() < 0.25 {
+			a.Phase++
+			if a.Phase == PhaseDone {
+				a.Status = StatusIdle
+				a.Progress = 100
+				a.TokensPerSec = 0
+				trackRetry(a, StatusRunning)
+				continue
+			}
+		}
+
+		// Progress: advance toward phase-appropriate percentage
+		targetPct := clamp(int(a.Phase+1)*14+rand.Intn(5), 0, 99)
+		if a.Progress < targetPct {
+			a.Progress += 1 + rand.Intn(4)
+			if a.Progress > targetPct {
+				a.Progress = targetPct
+			}
+		}
+
+		// Token throughput: fluctuate around model baseline
+		tokRange := modelTokRanges[a.Model]
+		base := (tokRange[0] + tokRange[1]) / 2
+		jitter := (rand.Float64() - 0.5) * (tokRange[1] - tokRange[0]) * 0.6
+		a.TokensPerSec = base + jitter
+		if a.TokensPerSec < 0 {
+			a.TokensPerSec = tokRange[0]
+		}
+
+		// Accumulate tokens (simulate ~2 seconds of throughput)
+		newOut := int(a.TokensPerSec * 2)
+		a.TotalTokensOut += newOut
+		a.TotalTokensIn += newOut * (2 + rand.Intn(3)) // input usually 2-4x output
+		a.TotalTokensCached += newOut * rand.Intn(4)   // prompt-cache reads on some turns
+
+		// Activity & tool usage
+		a.CurrentTool = pickRand(toolNames)
+		a.LastActivity = pickRand(activities)
+		a.LastActTime = now.Add(-time.Duration(rand.Intn(3)) * time.Second)
+		a.ToolsUsed++
+		// A Task call fans out into a sub-agent
+		if a.CurrentTool == "Task" && rand.Float32() < 0.4 && len(s.agents)+len(spawned) < 14 {
+			child := makeAgent()
+			child.ParentID = a.ID
+			child.Status = StatusRunning
+			child.StartedAt = now
+			child.Phase, child.Progress = PhaseObserve, 0
+			child.TotalTokensIn, child.TotalTokensOut, child.ToolsUsed = 0, 0, 0
+			child.TaskDesc = a.TaskDesc
+			beginPhases(&child, now, false)
+			a.LastActivity = "Task: spawned " + child.Name + " agent"
+			spawned = append(spawned, child)
+		}
+		entry := fmt.Sprintf("[%s] %s → %s",
+			now.Format("15:04:05"), a.CurrentTool, a.LastActivity)
+		a.EventLog = append(a.EventLog, entry)
+		if n := int(eventLogCap.Load()); len(a.EventLog) > n {
+			a.EventLog = a.EventLog[len(a.EventLog)-n:]
+		}
+
+		// Occasionally flip an ISC criterion
+		if rand.Float32() < 0.2 && len(a.ISCItems) > 0 {
+			idx := rand.Intn(len(a.ISCItems))
+			a.ISCItems[idx].Passed = !a.ISCItems[idx].Passed
+			a.ISCItems[idx].VerifiedAt = now
+		}
+	}
+
+	for i := range before {
+		trackPhase(&s.agents[i], before[i], now)
+	}
+	s.agents = append(s.agents, spawned...)
+
+	// Occasionally spawn or garbage-collect
+	if rand.Float32() < 0.12 && len(s.agents) < 14 {
+		s.agents = append(s.agents, makeAgent())
+	}
+	if rand.Float32() < 0.06 && len(s.agents) > 6 {
+		idx := rand.Intn(len(s.agents))
+		if s.agents[idx].Status == StatusStopped {
+			removed = append(removed, s.agents[idx].ID)
+			s.agents = append(s.agents[:idx], s.agents[idx+1:]...)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,183 @@
+package main
+
+// Table ordering — sort keys for the agent table.
+//
+// m.agents always stays in source order; rows() derives the displayed order
+// and the cursor indexes into that, so sorting never disturbs the source and
+// the selection is re-anchored by agent ID whenever the order changes.
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type sortKey int
+
+const (
+	sortNone sortKey = iota // source (insertion) order
+	sortID
+	sortName
+	sortStatus
+	sortPhase
+	sortProgress
+	sortTokens
+	sortUptime
+	sortKeyCount
+)
+
+var sortKeyNames = [...]string{"source order", "id", "name", "status", "phase", "progress", "tok/s", "uptime"}
+
+func (k sortKey) String() string { return sortKeyNames[k] }
+
+// less compares two agents in ascending order for the key.
+func (k sortKey) less(a, b Agent, now time.Time) bool {
+	switch k {
+	case sortID:
+		return a.ID < b.ID
+	case sortName:
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	case sortStatus:
+		return a.Status < b.Status
+	case sortPhase:
+		return a.Phase < b.Phase
+	case sortProgress:
+		return a.Progress < b.Progress
+	case sortTokens:
+		return shownTokRate(a) < shownTokRate(b)
+	case sortUptime:
+		return uptime(a, now) < uptime(b, now)
+	}
+	return false
+}
+
+// shownTokRate is the throughput the TOK/S column shows: only running agents
+// report one.
+func shownTokRate(a Agent) float64 {
+	if a.Status != StatusRunning {
+		return 0
+	}
+	return a.TokensPerSec
+}
+
+// uptime is how long the agent has been up; stopped agents count as zero.
+func uptime(a Agent, now time.Time) time.Duration {
+	if a.Status == StatusStopped {
+		return 0
+	}
+	return now.Sub(a.StartedAt)
+}
+
+// sortAgents orders agents in place. Ties keep source order, so rows do not
+// jitter between ticks.
+func sortAgents(agents []Agent, k sortKey, desc bool) {
+	if k == sortNone {
+		return
+	}
+	now := time.Now()
+	sort.SliceStable(agents, func(i, j int) bool {
+		if desc {
+			return k.less(agents[j], agents[i], now)
+		}
+		return k.less(agents[i], agents[j], now)
+	})
+}
+
+// rows returns the displayed agents in display order: those that pass the
+// filter, sorted, and laid out as a tree in tree view.
+func (m model) rows() []Agent {
+	tr := m.tableRows()
+	rows := make([]Agent, len(tr))
+	for i, r := range tr {
+		rows[i] = r.Agent
+	}
+	return rows
+}
+
+// selected returns the agent under the cursor.
+func (m model) selected() (Agent, bool) {
+	rows := m.rows()
+	if m.cursor < 0 || m.cursor >= len(rows) {
+		return Agent{}, false
+	}
+	return rows[m.cursor], true
+}
+
+// keepSelection moves the cursor to the row showing id, or clamps it if that
+// agent is no longer displayed.
+func (m *model) keepSelection(id string) {
+	rows := m.rows()
+	for i, a := range rows {
+		if a.ID == id {
+			m.cursor = i
+			return
+		}
+	}
+	m.cursor = clamp(m.cursor, 0, max(len(rows)-1, 0))
+}
+
+// moveCursor steps the cursor by delta rows, staying on the table.
+func (m *model) moveCursor(delta int) {
+	m.cursor = clamp(m.cursor+delta, 0, max(len(m.rows())-1, 0))
+}
+
+// halfPage is how far ctrl+d / ctrl+u move: half the table's visible rows.
+func (m model) halfPage() int {
+	return max(m.tableHeight()/2, 1)
+}
+
+// takeCount consumes a digit of a count prefix (5j, 12G). A leading 0 is
+// not a count.
+func (m *model) takeCount(msg tea.KeyMsg) bool {
+	d := msg.String()
+	if len(d) != 1 || d[0] < '0' || d[0] > '9' || (d == "0" && m.count == 0) {
+		return false
+	}
+	m.count = min(m.count*10+int(d[0]-'0'), 9999)
+	return true
+}
+
+// popCount returns the pending count, 1 when none was typed, and clears it.
+func (m *model) popCount() (n int, counted bool) {
+	n, counted = max(m.count, 1), m.count > 0
+	m.count = 0
+	return n, counted
+}
+
+// cycleSort advances to the next sort column (ascending), or flips the
+// direction when reverse is set.
+func (m *model) cycleSort(reverse bool) {
+	cur, _ := m.selected()
+	if reverse {
+		m.sortDesc = !m.sortDesc
+	} else {
+		m.sortBy = (m.sortBy + 1) % sortKeyCount
+		m.sortDesc = false
+	}
+	m.keepSelection(cur.ID)
+}
+
+// sortColumn sorts by k, or flips the direction when the table is already
+// sorted by it (a second click on the same header).
+func (m *model) sortColumn(k sortKey) {
+	cur, _ := m.selected()
+	if m.sortBy == k {
+		m.sortDesc = !m.sortDesc
+	} else {
+		m.sortBy, m.sortDesc = k, false
+	}
+	m.keepSelection(cur.ID)
+}
+
+// headerLabel decorates a column title with the active sort direction.
+func (m model) headerLabel(title string, k sortKey) string {
+	if m.sortBy != k || k == sortNone {
+		return title
+	}
+	if m.sortDesc {
+		return title + " ▼"
+	}
+	return title + " ▲"
+}
@@ -0,0 +1,105 @@
+package main
+
+// Agent sources — where the dashboard's agents come from.
+//
+// The model never mutates agent state on its own; it renders whatever the
+// active AgentSource publishes and forwards user actions back to it. New
+// backends (live PAI sessions, recordings, fixtures) plug in here.
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// AgentSource supplies agents to the dashboard.
+type AgentSource interface {
+	// Start begins producing updates. Snapshot is valid before Start.
+	Start()
+	// Stop ends the source and closes the Updates channel.
+	Stop()
+	// Snapshot returns a copy of every agent in display order.
+	Snapshot() []Agent
+	// Updates delivers incremental changes until the source stops.
+	Updates() <-chan AgentUpdate
+	// Refresh asks the source to publish fresh state as soon as possible.
+	Refresh()
+	// Control applies a user action to the agent with the given ID.
+	Control(id string, action ControlAction) error
+}
+
+// AgentUpdate is one incremental change from a source. Agents are upserted
+// by ID; Removed lists IDs that should disappear from the table.
+type AgentUpdate struct {
+	Agents  []Agent
+	Removed []string
+}
+
+// ControlAction is a user-initiated state change for a single agent.
+type ControlAction int
+
+const (
+	ActionStart ControlAction = iota
+	ActionStop
+	ActionPause
+	ActionResume
+)
+
+func (c ControlAction) String() string {
+	return [...]string{"start", "stop", "pause", "resume"}[c]
+}
+
+// errUnsupported is returned by sources that cannot perform an action.
+var errUnsupported = errors.New("not supported by this source")
+
+// sourceNames lists the values accepted by --source.
+var sourceNames = []string{"sim", "live", "replay"}
+
+// sourceOptions carries the flag values a source constructor may need.
+type sourceOptions struct {
+	historyDir string
+	workRoot   string
+	replayPath string
+}
+
+// newSource builds the source selected by --source.
+func newSource(name string, opts sourceOptions) (AgentSource, error) {
+	switch name {
+	case "sim":
+		return newSimulatedSource(10), nil
+	case "live":
+		return newLiveSource(opts.historyDir, opts.workRoot), nil
+	case "replay":
+		if opts.replayPath == "" {
+			return nil, errors.New("--source replay needs --replay FILE")
+		}
+		return loadReplay(opts.replayPath)
+	}
+	return nil, fmt.Errorf("unknown source %q (want one of: %s)", name, strings.Join(sourceNames, ", "))
+}
+
+// sourceUpdateMsg carries one AgentUpdate into model.Update.
+type sourceUpdateMsg AgentUpdate
+
+// waitForUpdate blocks until the source publishes its next update.
+func waitForUpdate(src AgentSource) tea.Cmd {
+	return func() tea.Msg {
+		u, ok := <-src.Updates()
+		if !ok {
+			return nil
+		}
+		return sourceUpdateMsg(u)
+	}
+}
+
+// copyAgent returns a deep copy so slices are never shared across goroutines.
+func copyAgent(a Agent) Agent {
+	a.ISCItems = append([]ISCCriterion(nil), a.ISCItems...)
+	a.EventLog = append([]string(nil), a.EventLog...)
+	a.Errors = append([]AgentError(nil), a.Errors...)
+	a.Question.Options = append([]string(nil), a.Question.Options...)
+	a.Phases = append([]PhaseSpan(nil), a.Phases...)
+	return a
+}
@@ -0,0 +1,241 @@
+package main
+
+// Spawn dialog — the modal form behind the 'n' key.
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SpawnRequest describes a new agent to create.
+type SpawnRequest struct {
+	Name     string
+	Model    string
+	Task     string
+	Criteria []string // optional ISC criteria
+}
+
+// Spawner is implemented by sources that can create agents on demand.
+type Spawner interface {
+	Spawn(req SpawnRequest) (Agent, error)
+}
+
+const maxTaskLen = 200
+
+// Validate checks a request before it is sent to a source.
+func (r SpawnRequest) Validate() error {
+	if !slices.Contains(agentNames, r.Name) {
+		return fmt.Errorf("unknown agent type %q", r.Name)
+	}
+	if !slices.Contains(models, r.Model) {
+		return fmt.Errorf("unknown model %q", r.Model)
+	}
+	task := strings.TrimSpace(r.Task)
+	if task == "" {
+		return errors.New("task description is required")
+	}
+	if len([]rune(task)) > maxTaskLen {
+		return fmt.Errorf("task description is longer than %d characters", maxTaskLen)
+	}
+	return nil
+}
+
+// newSpawnedAgent builds the initial state every source gives a spawned agent.
+func newSpawnedAgent(id string, req SpawnRequest, now time.Time) Agent {
+	isc := make([]ISCCriterion, 0, len(req.Criteria))
+	for _, c := range req.Criteria {
+		isc = append(isc, ISCCriterion{Text: c})
+	}
+	a := Agent{
+		ID:           id,
+		Name:         req.Name,
+		Status:       StatusRunning,
+		Phase:        PhaseObserve,
+		StartedAt:    now,
+		LastActTime:  now,
+		LastActivity: "spawned from dashboard",
+		Model:        req.Model,
+		TaskDesc:     strings.TrimSpace(req.Task),
+		ISCItems:     isc,
+		EventLog:     []string{fmt.Sprintf("[%s] spawned from dashboard", now.Format("15:04:05"))},
+	}
+	beginPhases(&a, now, false)
+	return a
+}
+
+// splitCriteria turns "a; b;; c" into ["a", "b", "c"].
+func splitCriteria(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ";") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Form
+// ---------------------------------------------------------------------------
+
+const (
+	fieldType = iota
+	fieldModel
+	fieldTask
+	fieldISC
+	fieldCount
+)
+
+// spawnForm is the modal shown while spawning. It is a value type owned by
+// the model, like the spinner and help bubbles.
+type spawnForm struct {
+	focus    int
+	typeIdx  int
+	modelIdx int
+	task     textinput.Model
+	isc      textinput.Model
+	err      string
+}
+
+// newSpawnForm pre-fills the form with the last request, if any.
+func newSpawnForm(last SpawnRequest) spawnForm {
+	task := textinput.New()
+	task.Placeholder = "What should the agent work on?"
+	task.CharLimit = maxTaskLen
+	task.SetValue(last.Task)
+	task.Cursor.SetMode(cursor.CursorStatic) // no blink messages to route
+
+	isc := textinput.New()
+	isc.Placeholder = "optional; separate criteria with ;"
+	isc.SetValue(strings.Join(last.Criteria, "; "))
+	isc.Cursor.SetMode(cursor.CursorStatic)
+
+	f := spawnForm{task: task, isc: isc}
+	f.typeIdx = max(0, slices.Index(agentNames, last.Name))
+	f.modelIdx = max(0, slices.Index(models, last.Model))
+	return f
+}
+
+// request reads the current form values.
+func (f spawnForm) request() SpawnRequest {
+	return SpawnRequest{
+		Name:     agentNames[f.typeIdx],
+		Model:    models[f.modelIdx],
+		Task:     strings.TrimSpace(f.task.Value()),
+		Criteria: splitCriteria(f.isc.Value()),
+	}
+}
+
+func (f *spawnForm) setFocus(i int) {
+	f.focus = (i + fieldCount) % fieldCount
+	f.task.Blur()
+	f.isc.Blur()
+	switch f.focus {
+	case fieldTask:
+		f.task.Focus()
+	case fieldISC:
+		f.isc.Focus()
+	}
+}
+
+// formResult is what a key press did to a modal form: the spawn dialog or
+// the input panel.
+type formResult int
+
+const (
+	formEditing formResult = iota
+	formSubmit
+	formCancel
+)
+
+func (f spawnForm) Update(msg tea.KeyMsg) (spawnForm, formResult, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		return f, formCancel, nil
+	case "enter":
+		if err := f.request().Validate(); err != nil {
+			f.err = err.Error()
+			return f, formEditing, nil
+		}
+		return f, formSubmit, nil
+	case "tab", "down":
+		f.setFocus(f.focus + 1)
+		return f, formEditing, nil
+	case "shift+tab", "up":
+		f.setFocus(f.focus - 1)
+		return f, formEditing, nil
+	}
+
+	switch f.focus {
+	case fieldType, fieldModel:
+		step := 0
+		switch msg.String() {
+		case "left", "h":
+			step = -1
+		case "right", "l", " ":
+			step = 1
+		}
+		if f.focus == fieldType {
+			f.typeIdx = (f.typeIdx + step + len(agentNames)) % len(agentNames)
+		} else {
+			f.modelIdx = (f.modelIdx + step + len(models)) % len(models)
+		}
+		return f, formEditing, nil
+	}
+
+	var cmd tea.Cmd
+	if f.focus == fieldTask {
+		f.task, cmd = f.task.Update(msg)
+	} else {
+		f.isc, cmd = f.isc.Update(msg)
+	}
+	f.err = ""
+	return f, formEditing, cmd
+}
+
+func (f spawnForm) View(w int, th Theme) string {
+	title := lipgloss.NewStyle().Bold(true).Foreground(th.Title)
+	label := lipgloss.NewStyle().Bold(true).Foreground(th.Fg).Width(12)
+	dim := lipgloss.NewStyle().Foreground(th.Dim)
+	focused := lipgloss.NewStyle().Foreground(th.Accent).Bold(true)
+
+	row := func(i int, name, value string) string {
+		marker := "  "
+		if f.focus == i {
+			marker = focused.Render("▶ ")
+		}
+		return marker + label.Render(name) + value
+	}
+	choice := func(i int, v string) string {
+		if f.focus == i {
+			return focused.Render("◀ " + v + " ▶")
+		}
+		return "  " + v
+	}
+
+	var b strings.Builder
+	b.WriteString(title.Render("Spawn New Agent") + "\n\n")
+	b.WriteString(row(fieldType, "Agent type", choice(fieldType, agentNames[f.typeIdx])) + "\n")
+	b.WriteString(row(fieldModel, "Model", choice(fieldModel, models[f.modelIdx])) + "\n")
+	b.WriteString(row(fieldTask, "Task", f.task.View()) + "\n")
+	b.WriteString(row(fieldISC, "ISC", f.isc.View()) + "\n\n")
+	if f.err != "" {
+		b.WriteString(lipgloss.NewStyle().Foreground(th.Error).Render("✗ "+f.err) + "\n")
+	}
+	b.WriteString(dim.Render("tab/↑↓ field • ←/→ choose • ⏎ spawn • esc cancel"))
+
+	boxW := min(w-4, 80)
+	return lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(th.Accent).
+		Padding(1, 2).Width(boxW).
+		Render(b.String())
+}
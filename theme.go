@@ -0,0 +1,180 @@
+package main
+
+// Themes — the palettes every render function draws with.
+//
+// A theme is picked with `theme = "..."` in the config file or cycled at
+// runtime with T. The default, "auto", asks the terminal for its background
+// color once at startup and uses Tokyo Night on dark terminals and
+// Solarized Light on light ones. NO_COLOR selects the monochrome theme,
+// which marks the selection with reverse video instead of a background,
+// whatever the config says; only cycling with T leaves it. Any [colors]
+// keys in the config are applied on top of the active theme, except under
+// NO_COLOR.
+
+import (
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is a complete palette.
+type Theme struct {
+	Name    string
+	Title   lipgloss.TerminalColor
+	Running lipgloss.TerminalColor
+	Idle    lipgloss.TerminalColor
+	Paused  lipgloss.TerminalColor
+	Error   lipgloss.TerminalColor
+	Stopped lipgloss.TerminalColor
+	Border  lipgloss.TerminalColor
+	Fg      lipgloss.TerminalColor
+	Dim     lipgloss.TerminalColor
+	SelBg   lipgloss.TerminalColor
+	Accent  lipgloss.TerminalColor // phases
+	Bar     lipgloss.TerminalColor // progress bar fill
+	BarBg   lipgloss.TerminalColor // progress bar empty
+	Mono    bool                   // no colors: selection uses reverse video
+}
+
+var themes = []Theme{
+	{
+		Name:  "tokyo-night",
+		Title: lipgloss.Color("#7aa2f7"), Running: lipgloss.Color("#e0af68"), Idle: lipgloss.Color("#9ece6a"),
+		Paused: lipgloss.Color("#7dcfff"), Error: lipgloss.Color("#f7768e"), Stopped: lipgloss.Color("#565f89"),
+		Border: lipgloss.Color("#3b4261"), Fg: lipgloss.Color("#c0caf5"), Dim: lipgloss.Color("#565f89"),
+		SelBg: lipgloss.Color("#283457"), Accent: lipgloss.Color("#bb9af7"),
+		Bar: lipgloss.Color("#9ece6a"), BarBg: lipgloss.Color("#1a1b26"),
+	},
+	{
+		Name:  "catppuccin",
+		Title: lipgloss.Color("#89b4fa"), Running: lipgloss.Color("#f9e2af"), Idle: lipgloss.Color("#a6e3a1"),
+		Paused: lipgloss.Color("#89dceb"), Error: lipgloss.Color("#f38ba8"), Stopped: lipgloss.Color("#6c7086"),
+		Border: lipgloss.Color("#45475a"), Fg: lipgloss.Color("#cdd6f4"), Dim: lipgloss.Color("#7f849c"),
+		SelBg: lipgloss.Color("#313244"), Accent: lipgloss.Color("#cba6f7"),
+		Bar: lipgloss.Color("#a6e3a1"), BarBg: lipgloss.Color("#45475a"),
+	},
+	{
+		Name:  "solarized-dark",
+		Title: lipgloss.Color("#268bd2"), Running: lipgloss.Color("#b58900"), Idle: lipgloss.Color("#859900"),
+		Paused: lipgloss.Color("#2aa198"), Error: lipgloss.Color("#dc322f"), Stopped: lipgloss.Color("#586e75"),
+		Border: lipgloss.Color("#586e75"), Fg: lipgloss.Color("#93a1a1"), Dim: lipgloss.Color("#657b83"),
+		SelBg: lipgloss.Color("#073642"), Accent: lipgloss.Color("#6c71c4"),
+		Bar: lipgloss.Color("#859900"), BarBg: lipgloss.Color("#073642"),
+	},
+	{
+		Name:  "solarized-light",
+		Title: lipgloss.Color("#268bd2"), Running: lipgloss.Color("#b58900"), Idle: lipgloss.Color("#859900"),
+		Paused: lipgloss.Color("#2aa198"), Error: lipgloss.Color("#dc322f"), Stopped: lipgloss.Color("#93a1a1"),
+		Border: lipgloss.Color("#93a1a1"), Fg: lipgloss.Color("#586e75"), Dim: lipgloss.Color("#93a1a1"),
+		SelBg: lipgloss.Color("#eee8d5"), Accent: lipgloss.Color("#6c71c4"),
+		Bar: lipgloss.Color("#859900"), BarBg: lipgloss.Color("#eee8d5"),
+	},
+	{
+		// Pure colors at full contrast, flipped for the terminal's background.
+		Name:    "high-contrast",
+		Title:   lipgloss.AdaptiveColor{Light: "#0000d7", Dark: "#00ffff"},
+		Running: lipgloss.AdaptiveColor{Light: "#875f00", Dark: "#ffff00"},
+		Idle:    lipgloss.AdaptiveColor{Light: "#005f00", Dark: "#00ff00"},
+		Paused:  lipgloss.AdaptiveColor{Light: "#005f87", Dark: "#5fd7ff"},
+		Error:   lipgloss.AdaptiveColor{Light: "#d70000", Dark: "#ff5f5f"},
+		Stopped: lipgloss.AdaptiveColor{Light: "#4e4e4e", Dark: "#bcbcbc"},
+		Border:  lipgloss.AdaptiveColor{Light: "#000000", Dark: "#ffffff"},
+		Fg:      lipgloss.AdaptiveColor{Light: "#000000", Dark: "#ffffff"},
+		Dim:     lipgloss.AdaptiveColor{Light: "#4e4e4e", Dark: "#bcbcbc"},
+		SelBg:   lipgloss.AdaptiveColor{Light: "#afd7ff", Dark: "#00005f"},
+		Accent:  lipgloss.AdaptiveColor{Light: "#870087", Dark: "#ff87ff"},
+		Bar:     lipgloss.AdaptiveColor{Light: "#005f00", Dark: "#00ff00"},
+		BarBg:   lipgloss.AdaptiveColor{Light: "#bcbcbc", Dark: "#4e4e4e"},
+	},
+	{
+		Name:  "monochrome",
+		Title: lipgloss.NoColor{}, Running: lipgloss.NoColor{}, Idle: lipgloss.NoColor{},
+		Paused: lipgloss.NoColor{}, Error: lipgloss.NoColor{}, Stopped: lipgloss.NoColor{},
+		Border: lipgloss.NoColor{}, Fg: lipgloss.NoColor{}, Dim: lipgloss.NoColor{},
+		SelBg: lipgloss.NoColor{}, Accent: lipgloss.NoColor{},
+		Bar: lipgloss.NoColor{}, BarBg: lipgloss.NoColor{},
+		Mono: true,
+	},
+}
+
+const themeAuto = "auto"
+
+// themeNames lists the accepted values of the theme config key.
+func themeNames() []string {
+	names := []string{themeAuto}
+	for _, t := range themes {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func themeIndex(name string) int {
+	for i, t := range themes {
+		if t.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// resolveTheme maps a configured theme name, including "auto", to a
+// palette. NO_COLOR wins over the name.
+func resolveTheme(name string, darkBg bool) Theme {
+	if noColor() {
+		return themes[themeIndex("monochrome")]
+	}
+	if i := themeIndex(name); i >= 0 {
+		return themes[i]
+	}
+	if darkBg {
+		return themes[themeIndex("tokyo-night")]
+	}
+	return themes[themeIndex("solarized-light")]
+}
+
+// noColor reports whether the user asked for no color (https://no-color.org).
+func noColor() bool { return os.Getenv("NO_COLOR") != "" }
+
+// nextTheme is the palette after t in the cycle.
+func nextTheme(t Theme) Theme {
+	return themes[(themeIndex(t.Name)+1)%len(themes)]
+}
+
+// withColors overrides the palette with any colors set in the config.
+func (t Theme) withColors(cc colorConfig) Theme {
+	pick := func(v string, def lipgloss.TerminalColor) lipgloss.TerminalColor {
+		if v == "" {
+			return def
+		}
+		return lipgloss.Color(v)
+	}
+	t.Title, t.Running, t.Idle = pick(cc.Title, t.Title), pick(cc.Running, t.Running), pick(cc.Idle, t.Idle)
+	t.Paused, t.Error, t.Stopped = pick(cc.Paused, t.Paused), pick(cc.Error, t.Error), pick(cc.Stopped, t.Stopped)
+	t.Border, t.Fg, t.Dim = pick(cc.Border, t.Border), pick(cc.Fg, t.Fg), pick(cc.Dim, t.Dim)
+	t.SelBg, t.Accent = pick(cc.SelBg, t.SelBg), pick(cc.Accent, t.Accent)
+	t.Bar, t.BarBg = pick(cc.Bar, t.Bar), pick(cc.BarBg, t.BarBg)
+	return t
+}
+
+// status is the color for an agent status.
+func (t Theme) status(s AgentStatus) lipgloss.TerminalColor {
+	return [...]lipgloss.TerminalColor{t.Running, t.Idle, t.Paused, t.Error, t.Stopped}[s]
+}
+
+// selected is the style of the row under the cursor.
+func (t Theme) selected() lipgloss.Style {
+	if t.Mono {
+		return lipgloss.NewStyle().Reverse(true)
+	}
+	return lipgloss.NewStyle().Background(t.SelBg)
+}
+
+// title renders a theme name for the status bar: "solarized-light" → "Solarized Light".
+func (t Theme) title() string {
+	words := strings.Split(t.Name, "-")
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,187 @@
+package main
+
+// Tree view — sub-agents nested under the agent that spawned them.
+//
+// Agents link to their spawner through ParentID. In tree mode rows() lays the
+// filtered agents out depth-first, siblings in the current sort order. An
+// agent whose parent is not displayed (filtered out, garbage-collected, or
+// never seen) is shown as a root. Parent rows report totals for their whole
+// subtree so a fan-out reads as one unit of work.
+
+// tableRow is one displayed row: the agent plus its place in the tree.
+type tableRow struct {
+	Agent
+	depth  int
+	prefix string // tree guides drawn before the name
+	kids   int    // direct children displayed under this row (even if collapsed)
+	total  subtree
+}
+
+// subtree aggregates an agent and all of its displayed descendants.
+type subtree struct {
+	Agents       int // descendants, not counting the agent itself
+	TokensPerSec float64
+	TokensIn     int
+	TokensOut    int
+	Tools        int
+	Cost         float64
+}
+
+// filtered returns the agents passing the filter, in source order. Unlike
+// rows() it ignores collapsed subtrees, so totals cover hidden children too.
+func (m model) filtered() []Agent {
+	out := make([]Agent, 0, len(m.agents))
+	for _, a := range m.agents {
+		if m.filter.matches(a, m.pricing) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// tableRows lays out the displayed rows: a sorted flat list, or the tree.
+func (m model) tableRows() []tableRow {
+	agents := m.filtered()
+	if !m.treeView {
+		sortAgents(agents, m.sortBy, m.sortDesc)
+		out := make([]tableRow, len(agents))
+		for i, a := range agents {
+			out[i] = tableRow{Agent: a}
+		}
+		return out
+	}
+
+	present := make(map[string]bool, len(agents))
+	for _, a := range agents {
+		present[a.ID] = true
+	}
+	var roots []Agent
+	children := map[string][]Agent{}
+	for _, a := range agents {
+		if a.ParentID != "" && a.ParentID != a.ID && present[a.ParentID] {
+			children[a.ParentID] = append(children[a.ParentID], a)
+		} else {
+			roots = append(roots, a)
+		}
+	}
+	sortAgents(roots, m.sortBy, m.sortDesc)
+
+	var out []tableRow
+	seen := map[string]bool{} // guards against ParentID cycles
+	var walk func(a Agent, depth int, guide string, last bool) subtree
+	walk = func(a Agent, depth int, guide string, last bool) subtree {
+		seen[a.ID] = true
+		row := tableRow{Agent: a, depth: depth}
+		if depth > 0 {
+			if last {
+				row.prefix, guide = guide+"└─", guide+"  "
+			} else {
+				row.prefix, guide = guide+"├─", guide+"│ "
+			}
+		}
+		idx := len(out)
+		out = append(out, row)
+
+		// Kids already drawn sit on a ParentID cycle and are skipped, so
+		// they must not count when finding the last one
+		var kids []Agent
+		for _, c := range children[a.ID] {
+			if !seen[c.ID] {
+				kids = append(kids, c)
+			}
+		}
+		sortAgents(kids, m.sortBy, m.sortDesc)
+		var sum subtree
+		for i, c := range kids {
+			out[idx].kids++
+			mark := len(out)
+			sub := walk(c, depth+1, guide, i == len(kids)-1)
+			if m.collapsed[a.ID] {
+				out = out[:mark]
+			}
+			sum.add(sub)
+		}
+		out[idx].total = sum
+		self := subtree{TokensPerSec: shownTokRate(a), TokensIn: a.TotalTokensIn,
+			TokensOut: a.TotalTokensOut, Tools: a.ToolsUsed, Cost: m.pricing.Cost(a)}
+		sum.add(self)
+		sum.Agents++
+		return sum
+	}
+	for _, r := range roots {
+		walk(r, 0, "", false)
+	}
+	// Anything left sits on a ParentID cycle; show it rather than lose it.
+	for _, a := range agents {
+		if !seen[a.ID] {
+			walk(a, 0, "", false)
+		}
+	}
+	return out
+}
+
+func (s *subtree) add(o subtree) {
+	s.Agents += o.Agents
+	s.TokensPerSec += o.TokensPerSec
+	s.TokensIn += o.TokensIn
+	s.TokensOut += o.TokensOut
+	s.Tools += o.Tools
+	s.Cost += o.Cost
+}
+
+// treeName renders the NAME cell: guides, an expand marker and the name.
+func (r tableRow) treeName(collapsed bool) string {
+	marker := ""
+	switch {
+	case r.kids > 0 && collapsed:
+		marker = "▸ "
+	case r.kids > 0:
+		marker = "▾ "
+	}
+	return r.prefix + marker + r.Name
+}
+
+// selectedSubtree totals the selected agent's descendants in tree view.
+func (m model) selectedSubtree() subtree {
+	rows := m.tableRows()
+	if !m.treeView || m.cursor < 0 || m.cursor >= len(rows) {
+		return subtree{}
+	}
+	return rows[m.cursor].total
+}
+
+// toggleTree switches between the flat table and the tree.
+func (m *model) toggleTree() {
+	cur, _ := m.selected()
+	m.treeView = !m.treeView
+	keys.Collapse.SetEnabled(m.treeView)
+	keys.Expand.SetEnabled(m.treeView)
+	m.keepSelection(cur.ID)
+}
+
+// collapse folds the selected agent's subtree, or moves to its parent when
+// there is nothing to fold.
+func (m *model) collapse() {
+	rows := m.tableRows()
+	if m.cursor >= len(rows) {
+		return
+	}
+	r := rows[m.cursor]
+	if r.kids > 0 && !m.collapsed[r.ID] {
+		m.collapsed[r.ID] = true
+		return
+	}
+	for i := m.cursor - 1; i >= 0; i-- {
+		if rows[i].depth < r.depth {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// expand unfolds the selected agent's subtree.
+func (m *model) expand() {
+	if a, ok := m.selected(); ok {
+		delete(m.collapsed, a.ID)
+	}
+}
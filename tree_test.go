@@ -0,0 +1,85 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTreeSubtreeTotals(t *testing.T) {
+	agent := func(id, parent string, st AgentStatus, rate float64, in, out, tools int) Agent {
+		return Agent{ID: id, Name: id, ParentID: parent, Model: "grok-3", Status: st,
+			TokensPerSec: rate, TotalTokensIn: in, TotalTokensOut: out, ToolsUsed: tools}
+	}
+	m := newModel(newSimulatedSource(1))
+	m.treeView = true
+	m.agents = []Agent{
+		agent("root", "", StatusRunning, 10, 100, 50, 1),
+		agent("a", "root", StatusRunning, 20, 1000, 500, 2),
+		agent("a1", "a", StatusIdle, 99, 10, 5, 3), // idle: its rate does not count
+		agent("b", "root", StatusRunning, 5, 0, 0, 4),
+		agent("orphan", "gone", StatusRunning, 7, 1, 1, 1),
+		agent("x", "y", StatusRunning, 1, 1, 1, 1), // a ParentID cycle
+		agent("y", "x", StatusRunning, 1, 1, 1, 1),
+	}
+	cost := func(ids ...string) float64 {
+		var sum float64
+		for _, a := range m.agents {
+			for _, id := range ids {
+				if a.ID == id {
+					sum += m.pricing.Cost(a)
+				}
+			}
+		}
+		return sum
+	}
+
+	tests := []struct {
+		id   string
+		kids int
+		want subtree
+	}{
+		{"root", 2, subtree{Agents: 3, TokensPerSec: 25, TokensIn: 1010, TokensOut: 505, Tools: 9, Cost: cost("a", "a1", "b")}},
+		{"a", 1, subtree{Agents: 1, TokensIn: 10, TokensOut: 5, Tools: 3, Cost: cost("a1")}},
+		{"b", 0, subtree{}},
+		{"orphan", 0, subtree{}},
+		{"x", 1, subtree{Agents: 1, TokensPerSec: 1, TokensIn: 1, TokensOut: 1, Tools: 1, Cost: cost("y")}},
+	}
+	check := func(label string, rows []tableRow, ids ...string) {
+		t.Helper()
+		byID := map[string]tableRow{}
+		for _, r := range rows {
+			if _, dup := byID[r.ID]; dup {
+				t.Errorf("%s: %s shown twice", label, r.ID)
+			}
+			byID[r.ID] = r
+		}
+		for _, tt := range tests {
+			r, ok := byID[tt.id]
+			if !ok {
+				continue
+			}
+			got, want := r.total, tt.want
+			if math.Abs(got.Cost-want.Cost) > 1e-9 {
+				t.Errorf("%s: %s cost %v, want %v", label, tt.id, got.Cost, want.Cost)
+			}
+			got.Cost, want.Cost = 0, 0
+			if got != want || r.kids != tt.kids {
+				t.Errorf("%s: %s total %+v with %d kids, want %+v with %d", label, tt.id, got, r.kids, want, tt.kids)
+			}
+		}
+		if len(rows) != len(ids) {
+			t.Errorf("%s: %d rows, want %d", label, len(rows), len(ids))
+		}
+		for _, id := range ids {
+			if _, ok := byID[id]; !ok {
+				t.Errorf("%s: %s not shown", label, id)
+			}
+		}
+	}
+
+	check("expanded", m.tableRows(), "root", "a", "a1", "b", "orphan", "x", "y")
+
+	// Folding hides the rows but keeps them in the totals
+	m.collapsed["root"] = true
+	check("collapsed", m.tableRows(), "root", "orphan", "x", "y")
+}